@@ -0,0 +1,87 @@
+package quest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Computes the delay before the next attempt of
+// an operation that keeps failing.
+// attempt is the number of failed attempts so far,
+// starting at 1.
+type Backoff interface {
+	Next(attempt int) time.Duration
+}
+
+// Regular functions can also be used as Backoff.
+type BackoffFn func(attempt int) time.Duration
+
+func (fn BackoffFn) Next(attempt int) time.Duration {
+	return fn(attempt)
+}
+
+// Always waits for the same delay.
+func ConstantBackoff(delay time.Duration) Backoff {
+	return BackoffFn(func(int) time.Duration {
+		return delay
+	})
+}
+
+// Doubles the delay on every attempt, starting from base,
+// up to max.
+// Example:
+//
+//	ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+//	// 100ms, 200ms, 400ms, 800ms, ... 5s, 5s
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return BackoffFn(func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if delay >= max || delay <= 0 {
+				return max
+			}
+		}
+		if delay > max {
+			return max
+		}
+		return delay
+	})
+}
+
+// Picks a random delay between base and three times
+// the previous delay, up to max. Spreads out retries
+// from many clients better than plain exponential backoff.
+// The returned Backoff is stateful: use one per operation,
+// attempt 1 starts over.
+func DecorrelatedJitterBackoff(base, max time.Duration) Backoff {
+	return &decorrelatedJitter{base: base, max: max, prev: base}
+}
+
+type decorrelatedJitter struct {
+	mu   sync.Mutex
+	base time.Duration
+	max  time.Duration
+	prev time.Duration
+}
+
+func (b *decorrelatedJitter) Next(attempt int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if attempt <= 1 {
+		b.prev = b.base
+	}
+
+	delay := b.base
+	if upper := b.prev * 3; upper > b.base {
+		delay += time.Duration(rand.Int63n(int64(upper - b.base)))
+	}
+	if delay > b.max {
+		delay = b.max
+	}
+
+	b.prev = delay
+	return delay
+}
@@ -0,0 +1,34 @@
+package quest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestBackoff(t *testing.T) {
+	constant := quest.ConstantBackoff(time.Second)
+	if constant.Next(1) != time.Second || constant.Next(10) != time.Second {
+		t.Error("constant backoff should not change")
+	}
+
+	exp := quest.ExponentialBackoff(100*time.Millisecond, time.Second)
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, d := range expected {
+		if got := exp.Next(i + 1); got != d*time.Millisecond {
+			t.Errorf("attempt %v: expected %v, got %v", i+1, d*time.Millisecond, got)
+		}
+	}
+	if exp.Next(100) != time.Second {
+		t.Error("exponential backoff should not overflow")
+	}
+
+	jitter := quest.DecorrelatedJitterBackoff(10*time.Millisecond, time.Second)
+	for i := 1; i <= 20; i++ {
+		d := jitter.Next(i)
+		if d < 10*time.Millisecond || d > time.Second {
+			t.Errorf("jitter out of bounds: %v", d)
+		}
+	}
+}
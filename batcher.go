@@ -0,0 +1,122 @@
+package quest
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// The error set by Fail() on batched tasks when the load
+// function returns a different number of values than keys.
+var ErrBatchMismatch = errors.New("batch load returned wrong number of values")
+
+// A Batcher collects individual loads into batches,
+// so that a single backend call serves many tasks.
+// A batch is loaded when it has maxBatch keys, or when
+// maxWait has passed since its first key was added.
+type Batcher[K comparable, V any] struct {
+	mu       sync.Mutex
+	maxBatch int
+	maxWait  time.Duration
+	loadFn   func([]K) ([]V, error)
+	batch    *pendingBatch[K, V]
+}
+
+type pendingBatch[K comparable, V any] struct {
+	keys  []K
+	tasks map[K]*taskImpl[V]
+	timer *time.Timer
+}
+
+// Creates a new batcher. loadFn must return the values
+// in the same order as the keys.
+// Example:
+//
+//	users := NewBatcher(100, 5*time.Millisecond, func(ids []int) ([]User, error) {
+//	  return db.GetUsers(ids)
+//	})
+//	a, b := users.Load(1), users.Load(2) // one query for both
+func NewBatcher[K comparable, V any](
+	maxBatch int,
+	maxWait time.Duration,
+	loadFn func([]K) ([]V, error),
+) *Batcher[K, V] {
+	return &Batcher[K, V]{
+		maxBatch: maxBatch,
+		maxWait:  maxWait,
+		loadFn:   loadFn,
+	}
+}
+
+// Adds the key to the current batch, and returns a task
+// that is resolved with its value once the batch is loaded.
+// Loading the same key twice in a batch returns the same task.
+// The task fails with the error returned by the load function.
+func (b *Batcher[K, V]) Load(key K) Task[V] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.batch == nil {
+		current := &pendingBatch[K, V]{tasks: map[K]*taskImpl[V]{}}
+		current.timer = time.AfterFunc(b.maxWait, func() {
+			b.flush(current)
+		})
+		b.batch = current
+	}
+
+	current := b.batch
+	if task, ok := current.tasks[key]; ok {
+		return task
+	}
+
+	task := newTask[V]()
+	current.keys = append(current.keys, key)
+	current.tasks[key] = task
+
+	if len(current.keys) >= b.maxBatch {
+		current.timer.Stop()
+		b.batch = nil
+		go b.load(current)
+	}
+
+	return task
+}
+
+// Loads the current batch right away.
+func (b *Batcher[K, V]) Flush() {
+	b.mu.Lock()
+	current := b.batch
+	b.mu.Unlock()
+	if current != nil {
+		b.flush(current)
+	}
+}
+
+func (b *Batcher[K, V]) flush(current *pendingBatch[K, V]) {
+	b.mu.Lock()
+	if b.batch != current {
+		b.mu.Unlock()
+		return
+	}
+	current.timer.Stop()
+	b.batch = nil
+	b.mu.Unlock()
+
+	b.load(current)
+}
+
+func (b *Batcher[K, V]) load(current *pendingBatch[K, V]) {
+	values, err := b.loadFn(current.keys)
+	if err == nil && len(values) != len(current.keys) {
+		err = ErrBatchMismatch
+	}
+
+	for i, key := range current.keys {
+		task := current.tasks[key]
+		if err != nil {
+			task.Fail(err)
+		} else {
+			task.Resolve(values[i])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestBatcher(t *testing.T) {
+	batches := make(chan []int, 10)
+	users := quest.NewBatcher(3, 10*time.Millisecond, func(ids []int) ([]string, error) {
+		batches <- ids
+		names := make([]string, len(ids))
+		for i, id := range ids {
+			names[i] = string(rune('a' + id))
+		}
+		return names, nil
+	})
+
+	t1 := users.Load(0)
+	t2 := users.Load(1)
+	if users.Load(0) != t1 {
+		t.Error("same key should return the same task")
+	}
+	t3 := users.Load(2)
+	if batch := <-batches; len(batch) != 3 {
+		t.Errorf("expected a full batch, got %v", batch)
+	}
+
+	t4 := users.Load(3)
+	if batch := <-batches; len(batch) != 1 {
+		t.Errorf("expected a partial batch after maxWait, got %v", batch)
+	}
+
+	for i, task := range []quest.Task[string]{t1, t2, t3, t4} {
+		if v, _ := task.Await(); v != string(rune('a'+i)) {
+			t.Errorf("wrong value for key %v: %v", i, v)
+		}
+	}
+}
+
+func TestBatcherError(t *testing.T) {
+	err := errors.New("nope")
+	b := quest.NewBatcher(10, time.Second, func(keys []int) ([]int, error) {
+		return nil, err
+	})
+
+	t1 := b.Load(1)
+	b.Flush()
+	if _, ok := t1.Await(); ok || t1.Error() != err {
+		t.Error("tasks should fail with the load error")
+	}
+}
@@ -0,0 +1,116 @@
+package quest
+
+import "sort"
+
+// Cancels all the tasks.
+// The tasks are all locked while being cancelled, so no
+// caller can observe some of them cancelled and others
+// still pending, e.g. during AwaitAll().
+func CancelAll[T any](tasks ...Task[T]) {
+	bulkSettle(tasks, func(task *taskImpl[T], i int) bool {
+		if !task.prepareSettle() {
+			return false
+		}
+		task.markCancelled(nil)
+		return true
+	}, func(task Task[T], i int) {
+		task.Cancel()
+	})
+}
+
+// Resolves each task with the value of the same index.
+// Same as CancelAll(), all tasks are locked while
+// being resolved.
+// Panics if the slices have different lengths.
+func ResolveAll[T any](tasks []Task[T], values []T) {
+	if len(tasks) != len(values) {
+		panic("quest: ResolveAll called with mismatched lengths")
+	}
+	bulkSettle(tasks, func(task *taskImpl[T], i int) bool {
+		if !task.prepareResolve() {
+			return false
+		}
+		task.value = values[i]
+		task.status = taskResolved
+		return true
+	}, func(task Task[T], i int) {
+		task.Resolve(values[i])
+	})
+}
+
+// Resets all the tasks, with all of them
+// locked in the meantime.
+func ResetAll[T any](tasks ...Task[T]) {
+	impls, others := lockAll(tasks)
+	for _, t := range impls {
+		if t.task.status != taskPending {
+			t.task.reset()
+		}
+	}
+	for _, t := range impls {
+		t.task.resolveMu.Unlock()
+	}
+	for _, t := range impls {
+		t.task.deliverChanges()
+	}
+	for _, i := range others {
+		tasks[i].Reset()
+	}
+}
+
+type indexedImpl[T any] struct {
+	task  *taskImpl[T]
+	index int
+}
+
+// Locks the tasks in ID order to avoid deadlocks with
+// concurrent bulk operations. Returns the locked tasks,
+// and the indices of the ones that are not *taskImpl.
+func lockAll[T any](tasks []Task[T]) ([]indexedImpl[T], []int) {
+	impls := make([]indexedImpl[T], 0, len(tasks))
+	var others []int
+	seen := map[*taskImpl[T]]bool{}
+	for i, task := range tasks {
+		impl, ok := task.(*taskImpl[T])
+		if !ok {
+			others = append(others, i)
+			continue
+		}
+		if !seen[impl] {
+			seen[impl] = true
+			impls = append(impls, indexedImpl[T]{impl, i})
+		}
+	}
+
+	sort.Slice(impls, func(i, j int) bool {
+		return impls[i].task.id < impls[j].task.id
+	})
+	for _, t := range impls {
+		t.task.resolveMu.Lock()
+	}
+
+	return impls, others
+}
+
+func bulkSettle[T any](
+	tasks []Task[T],
+	apply func(task *taskImpl[T], i int) bool,
+	fallback func(task Task[T], i int),
+) {
+	impls, others := lockAll(tasks)
+
+	var callbacks []func()
+	for _, t := range impls {
+		if apply(t.task, t.index) {
+			callbacks = append(callbacks, t.task.settle()...)
+		}
+	}
+	for _, t := range impls {
+		t.task.resolveMu.Unlock()
+	}
+
+	runCallbacks(callbacks)
+	for _, i := range others {
+		fallback(tasks[i], i)
+	}
+}
@@ -0,0 +1,38 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestBulkOperations(t *testing.T) {
+	tasks := []quest.Task[int]{
+		quest.NewTask[int](),
+		quest.NewTask[int](),
+		quest.NewTask[int](),
+	}
+
+	quest.ResolveAll(tasks, []int{1, 2, 3})
+	for i, task := range tasks {
+		if n, ok := task.Await(); n != i+1 || !ok {
+			t.Errorf("expected %v, got %v", i+1, n)
+		}
+	}
+
+	quest.ResetAll(tasks...)
+	for _, task := range tasks {
+		if task.IsDone() {
+			t.Error("tasks should be reset")
+		}
+	}
+
+	tasks[1].Resolve(10)
+	quest.CancelAll(tasks...)
+	if !tasks[0].IsCancelled() || !tasks[2].IsCancelled() {
+		t.Error("tasks should be cancelled")
+	}
+	if n, _ := tasks[1].Await(); n != 10 {
+		t.Error("resolved tasks should not be affected")
+	}
+}
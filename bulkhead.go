@@ -0,0 +1,135 @@
+package quest
+
+import (
+	"errors"
+	"sync"
+)
+
+// The error set by Fail() on tasks that
+// were not accepted for execution.
+var ErrRejected = errors.New("task rejected")
+
+// A Bulkhead limits how many functions run at the same
+// time, and how many can wait for their turn, so that one
+// misbehaving dependency can't use up all goroutines.
+// Functions are started with StartIn().
+type Bulkhead struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	maxQueued     int
+	running       int
+	queue         []bulkheadJob
+}
+
+type bulkheadJob struct {
+	task AnyTask
+	run  func()
+}
+
+// Creates a bulkhead that runs at most maxConcurrent
+// functions at a time, with at most maxQueued
+// functions waiting.
+// Panics if maxConcurrent is less than 1, or
+// maxQueued is negative.
+func NewBulkhead(maxConcurrent, maxQueued int) *Bulkhead {
+	if maxConcurrent < 1 || maxQueued < 0 {
+		panic("quest: NewBulkhead called with invalid limits")
+	}
+	return &Bulkhead{
+		maxConcurrent: maxConcurrent,
+		maxQueued:     maxQueued,
+	}
+}
+
+// Same as Start(), but runs fn inside the bulkhead.
+// If the bulkhead is full, the task fails with ErrRejected.
+// Cancelling the task while it is queued skips fn.
+// Example:
+//
+//	db := NewBulkhead(10, 100)
+//	rows := StartIn(db, func() []Row { return query(sql) })
+func StartIn[T any](b *Bulkhead, fn func() T) Task[T] {
+	task := newTask[T]()
+	job := bulkheadJob{task, func() {
+		task.Resolve(fn())
+	}}
+
+	b.mu.Lock()
+	rejected := false
+	switch {
+	case b.running < b.maxConcurrent:
+		b.running++
+		go b.run(job)
+	case len(b.queue) < b.maxQueued:
+		b.queue = append(b.queue, job)
+	default:
+		rejected = true
+	}
+	b.mu.Unlock()
+
+	// Failed without the lock, since callbacks
+	// of the task may start new functions.
+	if rejected {
+		task.Fail(ErrRejected)
+	}
+	return task
+}
+
+func (b *Bulkhead) run(job bulkheadJob) {
+	returned := false
+	defer func() {
+		// fn panicked: hand the slot over to the next
+		// function while the panic goes on.
+		if !returned {
+			if next, ok := b.next(); ok {
+				go b.run(next)
+			}
+		}
+	}()
+
+	for {
+		if !job.task.IsDone() {
+			job.run()
+		}
+		var ok bool
+		if job, ok = b.next(); !ok {
+			returned = true
+			return
+		}
+	}
+}
+
+// Takes the next queued function, or releases
+// the running slot if there is none.
+func (b *Bulkhead) next() (bulkheadJob, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.queue) == 0 {
+		b.running--
+		return bulkheadJob{}, false
+	}
+	job := b.queue[0]
+	b.queue[0] = bulkheadJob{}
+	b.queue = b.queue[1:]
+	return job, true
+}
+
+// Returns the number of running and queued functions.
+func (b *Bulkhead) Load() (running, queued int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.running, len(b.queue)
+}
+
+// Same as StartN(), but the replicas run inside the
+// bulkhead, so at most its maxConcurrent run at once.
+// Replicas that don't fit in the queue fail with ErrRejected.
+func StartNIn[T any](b *Bulkhead, n int, fn func(i int) T) []Task[T] {
+	tasks := make([]Task[T], n)
+	for i := range tasks {
+		i := i
+		tasks[i] = StartIn(b, func() T { return fn(i) })
+	}
+	return tasks
+}
@@ -0,0 +1,67 @@
+package quest_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestBulkhead(t *testing.T) {
+	b := quest.NewBulkhead(1, 1)
+	block := quest.NewVoidTask()
+	wait := func() quest.Void {
+		block.Await()
+		return quest.None
+	}
+
+	t1 := quest.StartIn(b, wait)
+	t2 := quest.StartIn(b, wait)
+	t3 := quest.StartIn(b, wait)
+
+	if running, queued := b.Load(); running != 1 || queued != 1 {
+		t.Errorf("expected 1 running and 1 queued, got %v and %v", running, queued)
+	}
+	if _, ok := t3.Await(); ok || t3.Error() != quest.ErrRejected {
+		t.Error("task should be rejected when the bulkhead is full")
+	}
+
+	block.Resolve(quest.None)
+	quest.AwaitAll[quest.Void](t1, t2)
+	if t1.IsCancelled() || t2.IsCancelled() {
+		t.Error("accepted tasks should be resolved")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic without room to run")
+		}
+	}()
+	quest.NewBulkhead(0, 10)
+}
+
+func TestStartNIn(t *testing.T) {
+	b := quest.NewBulkhead(2, 10)
+	var running, peak atomic.Int32
+	tasks := quest.StartNIn(b, 6, func(i int) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		randomSleep()
+		running.Add(-1)
+		return i
+	})
+
+	for i, r := range quest.AwaitAllSlice(tasks) {
+		if r.Value != i {
+			t.Errorf("replica %v: unexpected result %v", i, r)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent replicas, got %v", peak.Load())
+	}
+}
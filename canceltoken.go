@@ -0,0 +1,108 @@
+package quest
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Anything that can be cancelled, e.g. Task[T] of any type.
+type Cancellable interface {
+	Cancel()
+}
+
+// A CancelToken cancels every task attached to it at once.
+// Useful for cancelling everything that belongs to
+// a request, level or screen.
+// Example:
+//
+//	token := NewCancelToken()
+//	token.Attach(loadMap())
+//	token.Attach(loadMusic())
+//	// on screen close
+//	token.Cancel()
+type CancelToken struct {
+	mu sync.Mutex
+	// Keyed by cancelKey(), since not every
+	// Cancellable can be used as a map key.
+	tasks     map[any]Cancellable
+	cancelled bool
+}
+
+// Key of tasks that have an ID().
+type cancelID int64
+
+// Returns the key of the task in CancelToken.tasks:
+// its ID if it has one, the task itself if it is
+// comparable, or a new key otherwise.
+func cancelKey(task Cancellable) any {
+	if t, ok := task.(interface{ ID() int64 }); ok {
+		return cancelID(t.ID())
+	}
+	if reflect.TypeOf(task).Comparable() {
+		return task
+	}
+	return new(struct{ Cancellable })
+}
+
+// Creates a new cancel token.
+func NewCancelToken() *CancelToken {
+	return &CancelToken{tasks: map[any]Cancellable{}}
+}
+
+// Attaches the task to the token, so that it
+// is cancelled along with the token.
+// The task is cancelled immediately if the token
+// is already cancelled.
+// Tasks that are settled on their own are detached
+// automatically.
+func (token *CancelToken) Attach(task Cancellable) {
+	token.mu.Lock()
+	if token.cancelled {
+		token.mu.Unlock()
+		task.Cancel()
+		return
+	}
+	key := cancelKey(task)
+	token.tasks[key] = task
+	token.mu.Unlock()
+
+	if t, ok := task.(interface{ Defer(func()) }); ok {
+		t.Defer(func() { token.detach(key) })
+	}
+}
+
+// Detaches the task from the token,
+// without cancelling it.
+// Values that are neither tasks nor comparable
+// can't be detached, they stay attached until
+// they are settled or the token is cancelled.
+func (token *CancelToken) Detach(task Cancellable) {
+	token.detach(cancelKey(task))
+}
+
+func (token *CancelToken) detach(key any) {
+	token.mu.Lock()
+	defer token.mu.Unlock()
+	delete(token.tasks, key)
+}
+
+// Cancels all attached tasks, and any tasks
+// attached afterwards.
+func (token *CancelToken) Cancel() {
+	token.mu.Lock()
+	tasks := token.tasks
+	token.tasks = map[any]Cancellable{}
+	token.cancelled = true
+	token.mu.Unlock()
+
+	for _, task := range tasks {
+		task.Cancel()
+	}
+}
+
+// Returns true if Cancel() has been called.
+func (token *CancelToken) IsCancelled() bool {
+	token.mu.Lock()
+	defer token.mu.Unlock()
+	return token.cancelled
+}
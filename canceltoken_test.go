@@ -0,0 +1,62 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestCancelToken(t *testing.T) {
+	token := quest.NewCancelToken()
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[string]()
+	t3 := quest.NewVoidTask()
+
+	token.Attach(t1)
+	token.Attach(t2)
+	token.Attach(t3)
+	t3.Resolve(quest.None)
+
+	token.Cancel()
+	if !t1.IsCancelled() || !t2.IsCancelled() {
+		t.Error("attached tasks should be cancelled")
+	}
+	if t3.IsCancelled() {
+		t.Error("resolved tasks should not be affected")
+	}
+
+	t4 := quest.NewTask[int]()
+	token.Attach(t4)
+	if !t4.IsCancelled() {
+		t.Error("attaching to a cancelled token should cancel the task")
+	}
+}
+
+type cancelFuncs []func()
+
+func (fns cancelFuncs) Cancel() {
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func TestCancelTokenUncomparable(t *testing.T) {
+	token := quest.NewCancelToken()
+	cancelled := 0
+	fns := cancelFuncs{func() { cancelled++ }}
+	token.Attach(fns)
+	token.Attach(fns)
+	token.Detach(fns)
+
+	task := quest.NewTask[int]()
+	token.Attach(task)
+	token.Detach(task)
+
+	token.Cancel()
+	if cancelled != 2 {
+		t.Errorf("expected both attachments to be cancelled, got %v", cancelled)
+	}
+	if task.IsCancelled() {
+		t.Error("detached task should not be cancelled")
+	}
+}
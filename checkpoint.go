@@ -0,0 +1,103 @@
+package quest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Stores the resolved values of named tasks,
+// see WithCheckpointer().
+type Checkpointer interface {
+	// Returns the saved data of the task,
+	// ok is false if there is none.
+	Load(name string) (data []byte, ok bool, err error)
+	// Saves the data of the task.
+	Save(name string, data []byte) error
+}
+
+// Returns a checkpointer that saves each task's value
+// as a JSON file in the given directory.
+// The directory is created on the first save.
+func DirCheckpointer(dir string) Checkpointer {
+	return dirCheckpointer(dir)
+}
+
+type dirCheckpointer string
+
+func (dir dirCheckpointer) path(name string) string {
+	return filepath.Join(string(dir), url.PathEscape(name)+".json")
+}
+
+func (dir dirCheckpointer) Load(name string) ([]byte, bool, error) {
+	data, err := os.ReadFile(dir.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (dir dirCheckpointer) Save(name string, data []byte) error {
+	if err := os.MkdirAll(string(dir), 0o755); err != nil {
+		return err
+	}
+	tmp := dir.path(name) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir.path(name))
+}
+
+// Resolves the task with its saved value, if any.
+// Checkpoints that can't be loaded or decoded are
+// reported to the error hook, the task is then left pending.
+func (task *taskImpl[T]) restoreCheckpoint() {
+	if task.opts.name == "" {
+		return
+	}
+	data, ok, err := task.opts.checkpointer.Load(task.opts.name)
+	if err != nil {
+		task.checkpointError(err)
+		return
+	}
+	if !ok {
+		return
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		task.checkpointError(err)
+		return
+	}
+	task.value = value
+	task.status = taskResolved
+}
+
+// Returns a callback that saves the value.
+// Errors are reported to the error hook, the value
+// is then simply computed again on the next run.
+func (task *taskImpl[T]) saveCheckpoint(value T) func() {
+	return func() {
+		if task.opts.name == "" {
+			return
+		}
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = task.opts.checkpointer.Save(task.opts.name, data)
+		}
+		if err != nil {
+			task.checkpointError(err)
+		}
+	}
+}
+
+// Calls the hook set with WithCheckpointErrorHook(), if any.
+func (task *taskImpl[T]) checkpointError(err error) {
+	if fn := task.opts.checkpointFn; fn != nil {
+		fn(task.opts.name, err)
+	}
+}
@@ -0,0 +1,54 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestCheckpointer(t *testing.T) {
+	cp := quest.DirCheckpointer(t.TempDir())
+
+	t1 := quest.NewTask[[]int](quest.WithName("step/1"), quest.WithCheckpointer(cp))
+	if t1.IsDone() {
+		t.Error("task without checkpoint should be pending")
+	}
+	t1.Resolve([]int{1, 2, 3})
+
+	t2 := quest.NewTask[[]int](quest.WithName("step/1"), quest.WithCheckpointer(cp))
+	if v, ok := t2.Value(); !ok || len(v) != 3 || v[2] != 3 {
+		t.Errorf("task should be restored from the checkpoint, got %v", v)
+	}
+	if t2.Name() != "step/1" {
+		t.Errorf("wrong name: %v", t2.Name())
+	}
+
+	t3 := quest.NewTask[[]int](quest.WithName("step/2"), quest.WithCheckpointer(cp))
+	if t3.IsDone() {
+		t.Error("other names should not be restored")
+	}
+}
+
+type failingCheckpointer struct{ err error }
+
+func (cp failingCheckpointer) Load(name string) ([]byte, bool, error) { return nil, false, nil }
+func (cp failingCheckpointer) Save(name string, data []byte) error    { return cp.err }
+
+func TestCheckpointErrorHook(t *testing.T) {
+	err := errors.New("disk full")
+	var reported []string
+	task := quest.NewTask[int](
+		quest.WithName("step"),
+		quest.WithCheckpointer(failingCheckpointer{err}),
+		quest.WithCheckpointErrorHook(func(name string, e error) {
+			if e == err {
+				reported = append(reported, name)
+			}
+		}),
+	)
+	task.Resolve(1)
+	if len(reported) != 1 || reported[0] != "step" {
+		t.Errorf("expected the save error to be reported, got %v", reported)
+	}
+}
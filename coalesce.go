@@ -0,0 +1,93 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// Returns a view of the task where successive Resolve()
+// calls made within the window are merged: the first call
+// starts the window, and when it ends the task is resolved
+// once, with the latest value.
+// Meant for auto-reset tasks (see NewMultiTask()) that
+// are resolved in bursts, so consumers only see one event
+// per window. Resolve(), TryResolve() and ResolveWith()
+// always accept the value.
+// Cancel() and Fail() discard the pending value.
+// Example:
+//
+//	resized := Coalesce(NewMultiTask[Size](), 100*time.Millisecond)
+//	// resized.Resolve() on every window event,
+//	// layout only runs at most every 100ms
+func Coalesce[T any](task Task[T], window time.Duration) Task[T] {
+	return &coalescedTask[T]{Task: task, window: window}
+}
+
+type coalescedTask[T any] struct {
+	Task[T]
+	window time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+	value T
+}
+
+func (c *coalescedTask[T]) Resolve(value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value = value
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.window, c.flush)
+	}
+}
+
+func (c *coalescedTask[T]) TryResolve(value T) bool {
+	c.Resolve(value)
+	return true
+}
+
+func (c *coalescedTask[T]) ResolveWith(fn func() T) bool {
+	c.Resolve(fn())
+	return true
+}
+
+func (c *coalescedTask[T]) Cancel() {
+	c.discard()
+	c.Task.Cancel()
+}
+
+func (c *coalescedTask[T]) TryCancel() bool {
+	c.discard()
+	return c.Task.TryCancel()
+}
+
+func (c *coalescedTask[T]) Fail(err error) {
+	c.discard()
+	c.Task.Fail(err)
+}
+
+func (c *coalescedTask[T]) discard() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	var empty T
+	c.value = empty
+}
+
+func (c *coalescedTask[T]) flush() {
+	c.mu.Lock()
+	if c.timer == nil {
+		c.mu.Unlock()
+		return
+	}
+	value := c.value
+	c.timer = nil
+	c.mu.Unlock()
+
+	c.Task.Resolve(value)
+}
@@ -0,0 +1,41 @@
+package quest_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestCoalesce(t *testing.T) {
+	events := quest.NewMultiTask[int]()
+	coalesced := quest.Coalesce(events, 10*time.Millisecond)
+
+	var count atomic.Int32
+	quest.Watch(events, func(int) { count.Add(1) })
+
+	next := quest.Start(func() int {
+		n, _ := coalesced.Await()
+		return n
+	})
+	time.Sleep(2 * time.Millisecond)
+
+	for i := 1; i <= 5; i++ {
+		coalesced.Resolve(i)
+	}
+
+	if n, _ := next.Await(); n != 5 {
+		t.Errorf("expected the latest value, got %v", n)
+	}
+	if count.Load() != 1 {
+		t.Errorf("expected a single resolution, got %v", count.Load())
+	}
+
+	coalesced.Resolve(6)
+	coalesced.Cancel()
+	time.Sleep(20 * time.Millisecond)
+	if count.Load() != 1 {
+		t.Error("cancel should discard the pending value")
+	}
+}
@@ -0,0 +1,47 @@
+package quest
+
+import "sync"
+
+// The result of a task along with its position
+// in the list of tasks, see Completions().
+type IndexedResult[T any] struct {
+	Index int
+	Value T
+	OK    bool
+	// The error of the task if it failed, wrapped in
+	// a TaskError, nil otherwise.
+	Err error
+}
+
+// Returns a channel that receives the result of each task
+// as soon as it settles, in completion order. The channel
+// is closed after all tasks have settled.
+// Example:
+//
+//	for r := range Completions(downloads...) {
+//	  if r.OK { progress.Done(r.Index) }
+//	}
+func Completions[T any](tasks ...Awaitable[T]) <-chan IndexedResult[T] {
+	results := make(chan IndexedResult[T], len(tasks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(tasks))
+	for i, task := range tasks {
+		go func(i int, task Awaitable[T]) {
+			defer wg.Done()
+			value, ok := task.Await()
+			r := IndexedResult[T]{Index: i, Value: value, OK: ok}
+			if !ok {
+				r.Err = withIdentity(task, errorOf(task))
+			}
+			results <- r
+		}(i, task)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
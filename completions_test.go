@@ -0,0 +1,30 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestCompletions(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[int]()
+	t3 := quest.NewTask[int]()
+
+	results := quest.Completions[int](t1, t2, t3)
+	t2.Resolve(2)
+	if r := <-results; r.Index != 1 || r.Value != 2 || !r.OK {
+		t.Errorf("expected the second task first, got %+v", r)
+	}
+	t3.Cancel()
+	if r := <-results; r.Index != 2 || r.OK {
+		t.Errorf("expected the third task cancelled, got %+v", r)
+	}
+	t1.Resolve(1)
+	if r := <-results; r.Index != 0 || r.Value != 1 {
+		t.Errorf("expected the first task, got %+v", r)
+	}
+	if _, ok := <-results; ok {
+		t.Error("channel should be closed after all tasks settle")
+	}
+}
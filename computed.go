@@ -0,0 +1,88 @@
+package quest
+
+import "sync"
+
+// A task of any result type that notifies of its
+// resolutions. Every Task[T] satisfies this interface.
+type AnyAwaitable interface {
+	IsDone() bool
+	IsCancelled() bool
+	OnResolve(fn func()) (unsubscribe func())
+}
+
+// Returns a task holding the value computed by fn from
+// its dependencies. fn is called once all dependencies are
+// resolved, and again every time any of them is resolved
+// again, e.g. dependencies created with NewMultiTask() or
+// NewLatestTask(), or reset and resolved in a loop.
+// The returned task behaves like NewLatestTask(): Await()
+// returns the latest value, AwaitNext() waits for the next.
+// Cancelling the task, even once resolved, stops the
+// recomputations.
+// fn runs outside of any lock, so it may resolve its own
+// dependencies, which triggers another computation.
+// Example:
+//
+//	hp := NewLatestTask[int]()
+//	maxHP := NewLatestTask[int]()
+//	ratio := Computed(func() float64 {
+//	  a, _ := hp.Value()
+//	  b, _ := maxHP.Value()
+//	  return float64(a) / float64(b)
+//	}, hp, maxHP)
+func Computed[T any](fn func() T, deps ...AnyAwaitable) Task[T] {
+	task := newTask[T]()
+	task.overwrite = true
+	task.cancelResolved = true
+
+	ready := func() bool {
+		for _, dep := range deps {
+			if !dep.IsDone() || dep.IsCancelled() {
+				return false
+			}
+		}
+		return !task.IsCancelled()
+	}
+
+	// Only one computation runs at a time, so that the
+	// results are resolved in order. Changes during a
+	// computation make it run again once done.
+	var mu sync.Mutex
+	running, dirty := false, false
+	recompute := func() {
+		mu.Lock()
+		if running {
+			dirty = true
+			mu.Unlock()
+			return
+		}
+		running = true
+		for {
+			dirty = false
+			mu.Unlock()
+			if ready() {
+				task.Resolve(fn())
+			}
+			mu.Lock()
+			if !dirty {
+				running = false
+				mu.Unlock()
+				return
+			}
+		}
+	}
+
+	unsubscribes := make([]func(), len(deps))
+	for i, dep := range deps {
+		unsubscribes[i] = dep.OnResolve(recompute)
+	}
+	task.OnCancel(func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	})
+
+	recompute()
+
+	return task
+}
@@ -0,0 +1,55 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestComputed(t *testing.T) {
+	a := quest.NewLatestTask[int]()
+	b := quest.NewMultiTask[int]()
+	sum := quest.Computed(func() int {
+		x, _ := a.Value()
+		y, _ := b.Value()
+		return x + y
+	}, a, b)
+
+	a.Resolve(1)
+	if sum.IsDone() {
+		t.Error("should not compute before all dependencies are resolved")
+	}
+
+	b.Resolve(2)
+	if n, _ := sum.Value(); n != 3 {
+		t.Errorf("expected 3, got %v", n)
+	}
+
+	a.Resolve(10)
+	if n, _ := sum.Value(); n != 12 {
+		t.Errorf("expected 12, got %v", n)
+	}
+
+	sum.Cancel()
+	b.Resolve(20)
+	if _, ok := sum.Value(); ok {
+		t.Error("cancelled task should not recompute")
+	}
+}
+
+func TestComputedResolvesDependency(t *testing.T) {
+	// clamps its own input, which triggers a recomputation
+	a := quest.NewLatestTask[int]()
+	clamped := quest.Computed(func() int {
+		x, _ := a.Value()
+		if x < 0 {
+			a.Resolve(0)
+		}
+		return x
+	}, a)
+
+	a.Resolve(-5)
+	if n, _ := clamped.Value(); n != 0 {
+		t.Errorf("expected 0 after the recomputation, got %v", n)
+	}
+}
@@ -0,0 +1,46 @@
+package quest
+
+import "context"
+
+type contextKey struct{}
+
+type metaTask interface {
+	Meta(key any) any
+}
+
+// Returns a copy of ctx that carries the task, so that
+// the functions given to SubmitContext() with ctx find
+// its metadata, e.g. the ID of the request being served.
+// Example:
+//
+//	req := NewVoidTask()
+//	req.SetMeta("request-id", id)
+//	ctx = ContextWithTask(ctx, req)
+//	SubmitContext(exec, ctx, func(ctx context.Context) int {
+//	  log.Println(MetaFromContext(ctx, "request-id"))
+//	  ...
+//	})
+func ContextWithTask[T any](ctx context.Context, task Task[T]) context.Context {
+	return context.WithValue(ctx, contextKey{}, metaTask(task))
+}
+
+// Returns the metadata for the key of the task carried
+// by ctx, or nil if there is none.
+func MetaFromContext(ctx context.Context, key any) any {
+	if task, ok := ctx.Value(contextKey{}).(metaTask); ok {
+		return task.Meta(key)
+	}
+	return nil
+}
+
+// Copies the metadata of the task carried by ctx, if any,
+// to the task.
+func inheritMeta[T any](ctx context.Context, task *taskImpl[T]) {
+	parent, ok := ctx.Value(contextKey{}).(interface{ metadata() map[any]any })
+	if !ok {
+		return
+	}
+	for key, value := range parent.metadata() {
+		task.SetMeta(key, value)
+	}
+}
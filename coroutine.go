@@ -0,0 +1,113 @@
+package quest
+
+import (
+	"runtime"
+	"time"
+)
+
+// A task of any result type.
+// Every Task[T] satisfies this interface.
+type AnyTask interface {
+	IsDone() bool
+	IsCancelled() bool
+}
+
+// Handle passed to a coroutine started with
+// FrameScheduler.Coroutine(). Its methods suspend
+// the coroutine and must only be called from
+// within the coroutine itself.
+type Co struct {
+	scheduler *FrameScheduler
+	task      *taskImpl[Void]
+	resume    chan struct{}
+	yield     chan bool
+}
+
+// Starts fn as a coroutine. The coroutine only runs
+// during Update(), starting on the next one, and runs
+// until it suspends with Yield(), Await() or Sleep().
+// It is resumed on subsequent Update() calls.
+// fn runs on its own goroutine, but only while Update()
+// is blocked waiting for it to suspend, so it never runs
+// concurrently with the main thread or other coroutines,
+// and coroutines are resumed in the order they suspended.
+// The returned task is resolved when fn returns.
+// Cancelling the task stops the coroutine at its next
+// suspension point, where its goroutine exits with
+// runtime.Goexit(): deferred calls of fn are run, but
+// recover() can't keep the coroutine going.
+// Example:
+//
+//	scheduler.Coroutine(func(co *quest.Co) {
+//	  showDialog("Hello")
+//	  co.Sleep(2 * time.Second)
+//	  if co.Await(moveTo(npc, door)) {
+//	    openDoor()
+//	  }
+//	})
+func (s *FrameScheduler) Coroutine(fn func(co *Co)) VoidTask {
+	co := &Co{
+		scheduler: s,
+		task:      newTask[Void](),
+		resume:    make(chan struct{}),
+		yield:     make(chan bool),
+	}
+
+	go func() {
+		<-co.resume
+		defer func() {
+			co.task.Resolve(None)
+			co.yield <- true
+		}()
+		co.checkCancelled()
+		fn(co)
+	}()
+
+	s.Post(co.step)
+
+	return co.task
+}
+
+// Runs the coroutine until it suspends or finishes.
+func (co *Co) step() {
+	co.resume <- struct{}{}
+	if done := <-co.yield; !done {
+		co.scheduler.Post(co.step)
+	}
+}
+
+func (co *Co) checkCancelled() {
+	if co.task.IsCancelled() {
+		runtime.Goexit()
+	}
+}
+
+// Suspends the coroutine until the next Update().
+func (co *Co) Yield() {
+	co.yield <- false
+	<-co.resume
+	co.checkCancelled()
+}
+
+// Suspends the coroutine until the task is done.
+// Returns false if the task was cancelled.
+// The result can then be retrieved with task.Await()
+// without blocking.
+func (co *Co) Await(task AnyTask) bool {
+	for !task.IsDone() {
+		co.Yield()
+	}
+	return !task.IsCancelled()
+}
+
+// Suspends the coroutine until the game time has
+// advanced by at least d, see UpdateDelta(), so that
+// it follows pauses and slow motion.
+// Always yields at least once.
+func (co *Co) Sleep(d time.Duration) {
+	start := co.scheduler.GameTime()
+	co.Yield()
+	for co.scheduler.GameTime()-start < d {
+		co.Yield()
+	}
+}
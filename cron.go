@@ -0,0 +1,234 @@
+package quest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The number of run results buffered by a ScheduledJob.
+const scheduleResultsBuffer = 16
+
+// A ScheduledJob runs a function periodically,
+// following a cron expression. See Schedule().
+type ScheduledJob struct {
+	schedule CronSchedule
+	fn       func() error
+	results  *Stream[error]
+	stop     *taskImpl[Void]
+	task     *taskImpl[Void]
+}
+
+// Runs fn periodically according to the spec, until Stop().
+// Returns an error if the spec can't be parsed.
+// The spec is either a cron expression with five fields,
+// "minute hour day-of-month month day-of-week", each being
+// *, a number, a range a-b, a step */n or a-b/n, or a list
+// of these separated by commas; or one of @hourly, @daily,
+// @weekly, @monthly, @yearly, or "@every <duration>".
+// Runs never overlap: a run that is due while the previous
+// one is still running is skipped.
+// Example:
+//
+//	job, err := Schedule("*/15 * * * *", cleanupSessions)
+//	// ...
+//	job.Stop()
+func Schedule(spec string, fn func() error) (*ScheduledJob, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ScheduledJob{
+		schedule: schedule,
+		fn:       fn,
+		results:  NewStream[error](scheduleResultsBuffer),
+		stop:     newTask[Void](),
+		task:     newTask[Void](),
+	}
+	go job.run()
+	return job, nil
+}
+
+// Returns a stream of the errors returned by each run,
+// nil for successful ones. Results are dropped while the
+// buffer is full. The stream is closed once the job stops.
+func (job *ScheduledJob) Results() *Stream[error] {
+	return job.results
+}
+
+// Stops the job. A run in progress is not interrupted,
+// the task of the job is resolved once it has returned.
+func (job *ScheduledJob) Stop() {
+	job.stop.Resolve(None)
+}
+
+// Returns the task that is resolved once the job has
+// stopped, after Stop() and any run in progress.
+func (job *ScheduledJob) Task() VoidTask {
+	return job.task
+}
+
+func (job *ScheduledJob) run() {
+	defer job.task.Resolve(None)
+	defer job.results.Close()
+
+	stopped, abort := job.stop.AwaitAbortable()
+	defer abort()
+
+	for {
+		timer := time.NewTimer(time.Until(job.schedule.Next(time.Now())))
+		select {
+		case <-stopped:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if send := job.results.Send(job.fn()); !send.IsDone() {
+			send.Cancel()
+		}
+	}
+}
+
+// A parsed schedule spec, see ParseCron().
+type CronSchedule interface {
+	// Returns the first activation time after t.
+	Next(t time.Time) time.Time
+}
+
+type everySchedule time.Duration
+
+func (every everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(every))
+}
+
+// A cron expression, as sets of allowed values.
+type cronFields struct {
+	minute, hour, dom, month, dow uint64
+	// Set when the field is *, for the day matching rule.
+	domStar, dowStar bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parses a schedule spec, in the format described
+// in Schedule().
+// Example:
+//
+//	s, _ := ParseCron("0 9 * * 1-5")
+//	fmt.Println("next standup:", s.Next(time.Now()))
+func ParseCron(spec string) (CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid cron spec %q: bad duration", spec)
+		}
+		return everySchedule(d), nil
+	}
+	if expr, ok := cronDescriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron spec %q: expected 5 fields", spec)
+	}
+
+	var c cronFields
+	var err error
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	targets := [5]*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, field := range fields {
+		if *targets[i], err = parseCronField(field, bounds[i][0], bounds[i][1]); err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+		}
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return c, nil
+}
+
+// Parses a field into a bit set of the allowed values.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("bad step %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("bad value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("bad range %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c cronFields) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Gives up after a few years, for specs like "0 0 30 2 *"
+	// that never match.
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return limit
+}
+
+// Like cron, a day matches either field when both the
+// day of month and the day of week are restricted.
+func (c cronFields) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
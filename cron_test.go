@@ -0,0 +1,88 @@
+package quest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestCronNext(t *testing.T) {
+	start := time.Date(2024, 1, 31, 10, 7, 30, 0, time.UTC)
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 31, 13, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 6 29 2 *", time.Date(2024, 2, 29, 6, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * 1", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 6", time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", start.Add(90 * time.Second)},
+	}
+	for _, c := range cases {
+		schedule, err := quest.ParseCron(c.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.spec, err)
+			continue
+		}
+		if next := schedule.Next(start); !next.Equal(c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.spec, c.expected, next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every x"} {
+		if _, err := quest.ParseCron(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestScheduleStopWaitsForRun(t *testing.T) {
+	started := quest.NewVoidTask()
+	finish := quest.NewVoidTask()
+	job, err := quest.Schedule("@every 1ms", func() error {
+		started.Resolve(quest.None)
+		finish.Await()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started.Await()
+	job.Stop()
+	if job.Task().IsDone() {
+		t.Error("task should not be resolved while a run is in progress")
+	}
+	finish.Resolve(quest.None)
+	job.Task().Await()
+}
+
+func TestSchedule(t *testing.T) {
+	runs := 0
+	job, err := quest.Schedule("@every 5ms", func() error {
+		runs++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err, ok := job.Results().Next().Await(); !ok || err != nil {
+			t.Errorf("expected a successful run, got %v", err)
+		}
+	}
+	job.Stop()
+	for {
+		if _, ok := job.Results().Next().Await(); !ok {
+			break
+		}
+	}
+	if runs < 3 {
+		t.Errorf("expected at least 3 runs, got %v", runs)
+	}
+}
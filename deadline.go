@@ -0,0 +1,81 @@
+package quest
+
+import (
+	"errors"
+	"time"
+)
+
+// The error set by Fail() on tasks that
+// did not finish in time.
+var ErrTimeout = errors.New("task timed out")
+
+// Returns a task derived from the given task, that is
+// resolved or cancelled the same way, but fails with
+// ErrTimeout if the deadline is reached first.
+// Errors of the original task are wrapped in a TaskError.
+// The original task is left untouched, it can still be
+// awaited past the deadline.
+// Cancelling the derived task stops waiting for
+// the original.
+// Example:
+//
+//	user, ok := WithDeadline(fetchUser(id), time.Now().Add(time.Second)).Await()
+func WithDeadline[T any](task Awaitable[T], deadline time.Time) Task[T] {
+	derived := newTask[T]()
+
+	timer := time.AfterFunc(time.Until(deadline), func() {
+		derived.Fail(ErrTimeout)
+	})
+	derived.Defer(func() { timer.Stop() })
+
+	result, abort := awaitChan(task)
+	derived.Defer(abort)
+
+	go func() {
+		r, received := <-result
+		switch {
+		case !received:
+		case r.OK:
+			derived.Resolve(r.Value)
+		default:
+			derived.Fail(withIdentity(task, errorOf(task)))
+		}
+	}()
+
+	return derived
+}
+
+// Returns a task derived from the given task, that is
+// settled the same way, and calls onLate if the task
+// isn't done after d. Unlike WithDeadline(), the derived
+// task keeps waiting, it is only made visible that
+// the task is slow.
+// Errors of the task are wrapped in a TaskError.
+// onLate is called at most once, on its own goroutine.
+// Example:
+//
+//	report := WithSoftTimeout(buildReport(), 5*time.Second, func() {
+//	  log.Println("report is taking longer than usual")
+//	})
+func WithSoftTimeout[T any](task Awaitable[T], d time.Duration, onLate func()) Task[T] {
+	derived := newTask[T]()
+
+	timer := time.AfterFunc(d, onLate)
+	derived.Defer(func() { timer.Stop() })
+
+	result, abort := awaitChan(task)
+	derived.Defer(abort)
+
+	go func() {
+		r, received := <-result
+		switch {
+		case !received:
+		case r.OK:
+			derived.Resolve(r.Value)
+		default:
+			derived.Fail(withIdentity(task, errorOf(task)))
+		}
+	}()
+
+	return derived
+}
@@ -0,0 +1,69 @@
+package quest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestWithDeadline(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	derived := quest.WithDeadline[int](t1, time.Now().Add(10*time.Millisecond))
+
+	if _, ok := derived.Await(); ok {
+		t.Error("derived task should time out")
+	}
+	if derived.Error() != quest.ErrTimeout {
+		t.Errorf("expected ErrTimeout, got %v", derived.Error())
+	}
+	if t1.IsDone() {
+		t.Error("original task should be untouched")
+	}
+
+	t2 := quest.NewTask[int]()
+	derived = quest.WithDeadline[int](t2, time.Now().Add(time.Second))
+	t2.Resolve(5)
+	if n, ok := derived.Await(); n != 5 || !ok {
+		t.Errorf("expected 5, got %v", n)
+	}
+
+	err := errors.New("nope")
+	t3 := quest.NewTask[int]()
+	derived = quest.WithDeadline[int](t3, time.Now().Add(time.Second))
+	t3.Fail(err)
+	derived.Await()
+	var taskErr *quest.TaskError
+	if !errors.As(derived.Error(), &taskErr) || taskErr.ID != t3.ID() || !errors.Is(taskErr, err) {
+		t.Errorf("expected the original error, got %v", derived.Error())
+	}
+}
+
+func TestWithSoftTimeout(t *testing.T) {
+	var late atomic.Int32
+	t1 := quest.NewTask[int]()
+	derived := quest.WithSoftTimeout[int](t1, 5*time.Millisecond, func() { late.Add(1) })
+
+	time.Sleep(20 * time.Millisecond)
+	if late.Load() != 1 {
+		t.Errorf("onLate should be called once, got %v", late.Load())
+	}
+	if derived.IsDone() {
+		t.Error("derived task should keep waiting")
+	}
+	t1.Resolve(5)
+	if n, ok := derived.Await(); n != 5 || !ok {
+		t.Errorf("expected 5, got %v", n)
+	}
+
+	t2 := quest.NewTask[int]()
+	derived = quest.WithSoftTimeout[int](t2, 5*time.Millisecond, func() { late.Add(1) })
+	t2.Resolve(1)
+	derived.Await()
+	time.Sleep(20 * time.Millisecond)
+	if late.Load() != 1 {
+		t.Error("onLate should not be called for tasks done in time")
+	}
+}
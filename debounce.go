@@ -0,0 +1,49 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// Returns a function that starts fn once no calls have
+// been made for the duration d. All calls made in the
+// meantime return the same task, which is resolved with
+// the result of that single execution.
+// Calls made after fn has started begin a new cycle.
+// Example:
+//
+//	save := DebounceStart(500*time.Millisecond, saveDocument)
+//	onChange := func() { save() }
+func DebounceStart[T any](d time.Duration, fn func() T) func() Task[T] {
+	var mu sync.Mutex
+	var task *taskImpl[T]
+	var timer *time.Timer
+
+	fire := func(current *taskImpl[T]) {
+		mu.Lock()
+		if task != current {
+			// stale timer that was reset while firing
+			mu.Unlock()
+			return
+		}
+		task = nil
+		mu.Unlock()
+
+		current.Resolve(fn())
+	}
+
+	return func() Task[T] {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if task == nil {
+			current := newTask[T]()
+			task = current
+			timer = time.AfterFunc(d, func() { fire(current) })
+		} else {
+			timer.Reset(d)
+		}
+
+		return task
+	}
+}
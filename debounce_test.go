@@ -0,0 +1,32 @@
+package quest_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestDebounceStart(t *testing.T) {
+	calls := atomic.Int32{}
+	save := quest.DebounceStart(20*time.Millisecond, func() int32 {
+		return calls.Add(1)
+	})
+
+	t1 := save()
+	time.Sleep(5 * time.Millisecond)
+	t2 := save()
+	if t1 != t2 {
+		t.Error("calls within the duration should share a task")
+	}
+
+	t1.Await()
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %v", calls.Load())
+	}
+
+	if n, _ := save().Await(); n != 2 {
+		t.Errorf("expected a new cycle, got %v", n)
+	}
+}
@@ -0,0 +1,216 @@
+package quest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// The number of resolutions kept per task in debug mode.
+const DebugHistorySize = 8
+
+// The longest value summary kept in the history.
+const debugValueLength = 64
+
+var debugMode atomic.Bool
+
+// Enables or disables the debug mode. While enabled,
+// tasks remember their last DebugHistorySize resolutions,
+// which can be retrieved with DebugInfo().
+// It slows down every Resolve(), so it should only be
+// enabled while debugging.
+func SetDebug(enabled bool) {
+	debugMode.Store(enabled)
+}
+
+var orphanHook atomic.Pointer[func(id int64, name string)]
+
+// Sets a function called when a task is resolved while
+// nobody awaits or watches it, which often means that the
+// result is leaked or that the code path is dead.
+// Tasks awaited only after their resolution are also
+// reported, so it is mostly useful while debugging.
+// A nil fn disables the hook.
+// Example:
+//
+//	SetOrphanHook(func(id int64, name string) {
+//	  log.Printf("task %d (%s) resolved without awaiters", id, name)
+//	})
+func SetOrphanHook(fn func(id int64, name string)) {
+	if fn == nil {
+		orphanHook.Store(nil)
+		return
+	}
+	orphanHook.Store(&fn)
+}
+
+type idleCheck struct {
+	grace time.Duration
+	fn    func(id int64, name string)
+}
+
+var idleHook atomic.Pointer[idleCheck]
+
+// Sets a function called when a task was resolved, but its
+// result was not awaited or read within the grace period,
+// or before the task is freed with FreeTask().
+// Helps to find forgotten results and dead code paths.
+// A nil fn disables the hook.
+// Example:
+//
+//	SetIdleHook(time.Minute, func(id int64, name string) {
+//	  log.Printf("result of task %d (%s) was never used", id, name)
+//	})
+func SetIdleHook(grace time.Duration, fn func(id int64, name string)) {
+	if fn == nil {
+		idleHook.Store(nil)
+		return
+	}
+	idleHook.Store(&idleCheck{grace, fn})
+}
+
+// Returns a callback that reports the task to the idle hook
+// if the current result is still unused after the grace period.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) watchIdle(check *idleCheck) func() {
+	cycle := task.cycle
+	return func() {
+		time.AfterFunc(check.grace, func() {
+			task.resolveMu.Lock()
+			idle := task.cycle == cycle && task.status == taskResolved && !task.awaited
+			task.resolveMu.Unlock()
+			if idle {
+				check.fn(task.id, task.opts.name)
+			}
+		})
+	}
+}
+
+// Reports the task to the idle hook right away
+// if its result is unused. The idle check pending
+// since the last settlement, if any, is dropped so
+// that the task is not reported twice.
+func (task *taskImpl[T]) reportIfIdle() {
+	check := idleHook.Load()
+	if check == nil {
+		return
+	}
+	task.resolveMu.Lock()
+	task.cycle++
+	idle := task.status == taskResolved && !task.awaited
+	task.resolveMu.Unlock()
+	if idle {
+		check.fn(task.id, task.opts.name)
+	}
+}
+
+// A snapshot of a task, returned by DebugInfo().
+type TaskDebugInfo struct {
+	ID     int64
+	Name   string
+	Status Status
+	// The last resolutions recorded in debug mode,
+	// oldest first.
+	History []Resolution
+}
+
+// A resolution recorded in debug mode.
+type Resolution struct {
+	// The resolved value, formatted with %v and truncated.
+	Value string
+	Time  time.Time
+	// The function and line that resolved the task.
+	Caller string
+}
+
+// Returns a snapshot of the task, with the history
+// of the resolutions made while SetDebug(true).
+// Example:
+//
+//	SetDebug(true)
+//	// ...
+//	for _, r := range DebugInfo(task).History {
+//	  fmt.Println(r.Time, r.Caller, r.Value)
+//	}
+func DebugInfo[T any](task Task[T]) TaskDebugInfo {
+	info := TaskDebugInfo{
+		ID:     task.ID(),
+		Name:   task.Name(),
+		Status: task.Status(),
+	}
+	if impl, ok := task.(*taskImpl[T]); ok {
+		info.History = impl.resolutions()
+	}
+	return info
+}
+
+// A fixed-size buffer of the last resolutions.
+type resolutionRing struct {
+	entries [DebugHistorySize]Resolution
+	next    int
+	count   int
+}
+
+func (ring *resolutionRing) add(r Resolution) {
+	ring.entries[ring.next] = r
+	ring.next = (ring.next + 1) % DebugHistorySize
+	if ring.count < DebugHistorySize {
+		ring.count++
+	}
+}
+
+func (ring *resolutionRing) list() []Resolution {
+	result := make([]Resolution, 0, ring.count)
+	start := ring.next - ring.count
+	if start < 0 {
+		start += DebugHistorySize
+	}
+	for i := 0; i < ring.count; i++ {
+		result = append(result, ring.entries[(start+i)%DebugHistorySize])
+	}
+	return result
+}
+
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) recordResolution() {
+	if task.history == nil {
+		task.history = &resolutionRing{}
+	}
+	value := fmt.Sprintf("%v", task.value)
+	if len(value) > debugValueLength {
+		value = value[:debugValueLength] + "..."
+	}
+	task.history.add(Resolution{
+		Value:  value,
+		Time:   time.Now(),
+		Caller: externalCaller(),
+	})
+}
+
+func (task *taskImpl[T]) resolutions() []Resolution {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	if task.history == nil {
+		return nil
+	}
+	return task.history.list()
+}
+
+// Returns the first caller outside of this package.
+func externalCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/nvlled/quest.") {
+			return fmt.Sprintf("%s:%d", frame.Function, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
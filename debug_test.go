@@ -0,0 +1,134 @@
+package quest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nvlled/mud"
+	"github.com/nvlled/quest"
+)
+
+func TestDebugInfo(t *testing.T) {
+	quest.SetDebug(true)
+	defer quest.SetDebug(false)
+
+	task := quest.NewTask[int](quest.WithName("counter"))
+	for i := 0; i < quest.DebugHistorySize+2; i++ {
+		task.Reset()
+		task.Resolve(i)
+	}
+
+	info := quest.DebugInfo(task)
+	if info.Name != "counter" || info.Status != quest.Resolved {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if len(info.History) != quest.DebugHistorySize {
+		t.Fatalf("expected %v entries, got %v", quest.DebugHistorySize, len(info.History))
+	}
+	if info.History[0].Value != "2" || info.History[len(info.History)-1].Value != "9" {
+		t.Errorf("expected the last resolutions, got %+v", info.History)
+	}
+	if !strings.Contains(info.History[0].Caller, "TestDebugInfo") {
+		t.Errorf("expected the caller to be the test, got %v", info.History[0].Caller)
+	}
+}
+
+func TestOrphanHook(t *testing.T) {
+	var orphans []string
+	quest.SetOrphanHook(func(id int64, name string) {
+		if name != "" {
+			orphans = append(orphans, name)
+		}
+	})
+	defer quest.SetOrphanHook(nil)
+
+	quest.NewTask[int](quest.WithName("orphan")).Resolve(1)
+
+	awaited := quest.NewTask[int](quest.WithName("awaited"))
+	done := quest.Start(func() int {
+		n, _ := awaited.Await()
+		return n
+	})
+	for awaited.WaiterCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	awaited.Resolve(2)
+	done.Await()
+	if awaited.WaiterCount() != 0 {
+		t.Errorf("expected no waiters, got %v", awaited.WaiterCount())
+	}
+
+	if len(orphans) != 1 || orphans[0] != "orphan" {
+		t.Errorf("expected only the orphan task, got %v", orphans)
+	}
+}
+
+func TestIdleHook(t *testing.T) {
+	idle := make(chan string, 10)
+	quest.SetIdleHook(10*time.Millisecond, func(id int64, name string) {
+		if name != "" {
+			idle <- name
+		}
+	})
+	defer quest.SetIdleHook(0, nil)
+
+	quest.NewTask[int](quest.WithName("forgotten")).Resolve(1)
+
+	used := quest.NewTask[int](quest.WithName("used"))
+	used.Resolve(2)
+	used.Await()
+
+	select {
+	case name := <-idle:
+		if name != "forgotten" {
+			t.Errorf("expected the forgotten task, got %v", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the forgotten task to be reported")
+	}
+	select {
+	case name := <-idle:
+		t.Errorf("unexpected report of %v", name)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	pool := mud.NewPool()
+	pooled := quest.NewTask[int](quest.WithName("freed"), quest.WithPool(pool))
+	pooled.Resolve(3)
+	quest.FreeTaskIn(pool, pooled)
+	if name := <-idle; name != "freed" {
+		t.Errorf("expected the freed task, got %v", name)
+	}
+	select {
+	case name := <-idle:
+		t.Errorf("freed task should only be reported once, got %v", name)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	err := errors.New("nope")
+	task := quest.NewTask[int](quest.WithName("loader"), quest.WithStackTrace())
+	task.Fail(err)
+
+	var taskErr *quest.TaskError
+	if !errors.As(task.Error(), &taskErr) {
+		t.Fatalf("expected a task error, got %v", task.Error())
+	}
+	if !errors.Is(task.Error(), err) || taskErr.Name != "loader" || taskErr.ID != task.ID() {
+		t.Errorf("unexpected error: %v", taskErr)
+	}
+	if !strings.Contains(string(taskErr.Stack), "TestStackTrace") {
+		t.Errorf("expected the stack of the caller, got %s", taskErr.Stack)
+	}
+
+	joined := quest.AwaitAllErr[int](task)
+	if joined.Error() != "task 0: nope" {
+		t.Errorf("identity should not be repeated, got %v", joined)
+	}
+	if leaf, ok := quest.LeafTaskError(joined); !ok || leaf.Stack == nil {
+		t.Errorf("expected the stack to be kept, got %v", leaf)
+	}
+}
@@ -0,0 +1,60 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// Returns a cold task: unlike Start(), fn is not run
+// until the task is first awaited, with Await(), AwaitNext(),
+// AwaitAbortable() or AwaitWithTicker(), or by a combinator
+// that awaits it.
+// Cancelling the task before then prevents fn from running.
+// fn runs at most once, in its own goroutine.
+// Example:
+//
+//	fromCache := Deferred(loadFromCache)
+//	fromNetwork := Deferred(loadFromNetwork)
+//	// loadFromNetwork is not called if the cache
+//	// already answered
+//	AwaitSome[Data](fromCache, fromNetwork)
+func Deferred[T any](fn func() T) Task[T] {
+	return &deferredTask[T]{taskImpl: newTask[T](), fn: fn}
+}
+
+type deferredTask[T any] struct {
+	*taskImpl[T]
+	fn   func() T
+	once sync.Once
+}
+
+func (task *deferredTask[T]) start() {
+	task.once.Do(func() {
+		if task.IsDone() {
+			return
+		}
+		go func() {
+			task.Resolve(task.fn())
+		}()
+	})
+}
+
+func (task *deferredTask[T]) Await() (T, bool) {
+	task.start()
+	return task.taskImpl.Await()
+}
+
+func (task *deferredTask[T]) AwaitNext() (T, bool) {
+	task.start()
+	return task.taskImpl.AwaitNext()
+}
+
+func (task *deferredTask[T]) AwaitAbortable() (<-chan Result[T], func()) {
+	task.start()
+	return task.taskImpl.AwaitAbortable()
+}
+
+func (task *deferredTask[T]) AwaitWithTicker(interval time.Duration, onTick func(time.Duration)) (T, bool) {
+	task.start()
+	return task.taskImpl.AwaitWithTicker(interval, onTick)
+}
@@ -0,0 +1,76 @@
+package quest_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestDeferred(t *testing.T) {
+	var calls atomic.Int32
+	task := quest.Deferred(func() int {
+		calls.Add(1)
+		return 1
+	})
+
+	randomSleep()
+	if calls.Load() != 0 {
+		t.Error("deferred task should not start before Await()")
+	}
+	if n, _ := task.Await(); n != 1 {
+		t.Errorf("expected 1, got %v", n)
+	}
+	task.Await()
+	if calls.Load() != 1 {
+		t.Errorf("expected a single call, got %v", calls.Load())
+	}
+
+	cancelled := quest.Deferred(func() int {
+		calls.Add(1)
+		return 2
+	})
+	cancelled.Cancel()
+	if _, ok := cancelled.Await(); ok || calls.Load() != 1 {
+		t.Error("cancelled deferred task should never start")
+	}
+}
+
+func TestDeferredAwaitVariants(t *testing.T) {
+	awaits := map[string]func(quest.Task[int]) (int, bool){
+		"Await":     quest.Task[int].Await,
+		"AwaitNext": quest.Task[int].AwaitNext,
+		"AwaitAbortable": func(task quest.Task[int]) (int, bool) {
+			result, _ := task.AwaitAbortable()
+			r := <-result
+			return r.Value, r.OK
+		},
+		"AwaitWithTicker": func(task quest.Task[int]) (int, bool) {
+			return task.AwaitWithTicker(time.Hour, func(time.Duration) {})
+		},
+	}
+	for name, await := range awaits {
+		task := quest.Deferred(func() int { return 1 })
+		if n, ok := await(task); !ok || n != 1 {
+			t.Errorf("%v should start the deferred task, got %v", name, n)
+		}
+	}
+}
+
+func TestAwaitSomeDeferred(t *testing.T) {
+	done := quest.NewTask[int]()
+	done.Resolve(1)
+
+	var started atomic.Bool
+	cold := quest.Deferred(func() int {
+		started.Store(true)
+		return 2
+	})
+
+	quest.AwaitSome[int](done, cold)
+	randomSleep()
+	if started.Load() {
+		t.Error("cold task should not be started when another task is done")
+	}
+}
@@ -0,0 +1,70 @@
+package quest
+
+// Returns a shared, already resolved void task.
+// Useful for functions that must return a VoidTask
+// but have nothing to do, without allocating a task.
+// The task is immutable: Reset(), Resolve(), Cancel(),
+// Fail() and the other setters have no effect.
+// Since it is never settled again, AwaitNext() returns
+// right away, OnResolve() and Watch() never call back,
+// and StateChanges() returns a closed stream.
+// Example:
+//
+//	func (s *Screen) Close() VoidTask {
+//	  if s.closed {
+//	    return Done()
+//	  }
+//	  // ...
+//	}
+func Done() VoidTask {
+	return doneSingleton
+}
+
+var doneSingleton VoidTask = newFrozenTask(None)
+
+// Returns a task that ignores everything: Resolve(),
+// Cancel() and the other setters have no effect, and
+// Await() returns the zero value right away.
+// It behaves like Done() otherwise.
+// Useful for benchmarks, disabled code paths, and APIs
+// that require a task that won't be used.
+// Example:
+//
+//	var progress Task[int] = Discard[int]()
+//	if showProgress {
+//	  progress = NewTask[int]()
+//	}
+func Discard[T any]() Task[T] {
+	var empty T
+	return newFrozenTask(empty)
+}
+
+// A resolved task that can't be changed.
+type frozenTask[T any] struct {
+	*taskImpl[T]
+}
+
+func newFrozenTask[T any](value T) *frozenTask[T] {
+	task := newTask[T]()
+	task.resolve(value)
+	return &frozenTask[T]{task}
+}
+
+func (frozenTask[T]) Reset() bool               { return false }
+func (frozenTask[T]) ResetAfterAwaiters() bool  { return false }
+func (frozenTask[T]) Resolve(T)                 {}
+func (frozenTask[T]) TryResolve(T) bool         { return false }
+func (frozenTask[T]) ResolveWith(func() T) bool { return false }
+func (frozenTask[T]) Cancel()                   {}
+func (frozenTask[T]) TryCancel() bool           { return false }
+func (frozenTask[T]) Fail(error)                {}
+func (frozenTask[T]) SetPanic(bool)             {}
+func (frozenTask[T]) SetDefaultValue(T)         {}
+func (frozenTask[T]) SetCancelError(error)      {}
+func (frozenTask[T]) SetMeta(key, value any)    {}
+
+// Nothing is kept for settlements that never come.
+func (task frozenTask[T]) AwaitNext() (T, bool)     { return task.taskImpl.Await() }
+func (frozenTask[T]) OnResolve(func()) func()       { return func() {} }
+func (frozenTask[T]) watch(func(T)) func()          { return func() {} }
+func (frozenTask[T]) StateChanges() *Stream[Status] { return StreamOf[Status]() }
@@ -0,0 +1,41 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestDone(t *testing.T) {
+	done := quest.Done()
+	if done != quest.Done() {
+		t.Error("expected a shared task")
+	}
+
+	done.Reset()
+	done.Cancel()
+	if _, ok := done.Await(); !ok || done.Status() != quest.Resolved {
+		t.Error("Done() should stay resolved")
+	}
+
+	if _, ok := done.AwaitNext(); !ok {
+		t.Error("AwaitNext() should return right away")
+	}
+	done.OnResolve(func() { t.Error("unexpected callback") })()
+	quest.Watch(done, func(quest.Void) { t.Error("unexpected callback") })()
+	if _, ok := done.StateChanges().Next().Await(); ok {
+		t.Error("expected a closed stream")
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	task := quest.Discard[int]()
+	task.Resolve(1)
+	task.Fail(nil)
+	if n, _ := task.Await(); n != 0 {
+		t.Errorf("expected the zero value, got %v", n)
+	}
+	if task.TryResolve(2) || task.Reset() {
+		t.Error("discarded task should ignore everything")
+	}
+}
@@ -0,0 +1,253 @@
+package quest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Converts the items of a DurableQueue to and from bytes.
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// Returns a codec that uses encoding/json.
+func JSONCodec[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// A DurableQueue is a job queue persisted in a directory,
+// one file per job, so that pushed jobs survive restarts.
+// Jobs handed out by Pop() must be acknowledged with Ack()
+// once done. Jobs that are not acknowledged before their
+// lease expires, or that are Nack()'d, go back to the queue.
+// Jobs that were leased when the process stopped are back
+// in the queue on the next open.
+type DurableQueue[T any] struct {
+	mu      sync.Mutex
+	dir     string
+	codec   Codec[T]
+	lease   time.Duration
+	nextSeq uint64
+
+	ready   []*Job[T]
+	leased  map[uint64]*Job[T]
+	waiting []*taskImpl[*Job[T]]
+	// Callbacks of the Pop() tasks resolved with mu held,
+	// run by unlock().
+	callbacks []func()
+}
+
+// A job handed out by DurableQueue.Pop().
+type Job[T any] struct {
+	Value T
+
+	seq   uint64
+	queue *DurableQueue[T]
+	timer *time.Timer
+}
+
+const durableQueueExt = ".job"
+
+// Opens the queue stored in dir, creating the directory
+// if needed. Leased jobs go back to the queue if they are
+// not acknowledged within the lease duration.
+// Example:
+//
+//	queue, err := OpenDurableQueue("jobs", JSONCodec[Email](), time.Minute)
+//	queue.Push(Email{To: "someone@example.com"})
+//	job, _ := queue.Pop().Await()
+//	if send(job.Value) == nil { job.Ack() } else { job.Nack() }
+func OpenDurableQueue[T any](dir string, codec Codec[T], lease time.Duration) (*DurableQueue[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &DurableQueue[T]{
+		dir:     dir,
+		codec:   codec,
+		lease:   lease,
+		nextSeq: 1,
+		leased:  map[uint64]*Job[T]{},
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, durableQueueExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, durableQueueExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		value, err := codec.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding job %s: %w", name, err)
+		}
+		q.ready = append(q.ready, &Job[T]{Value: value, seq: seq, queue: q})
+		if seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(q.ready, func(i, j int) bool {
+		return q.ready[i].seq < q.ready[j].seq
+	})
+
+	return q, nil
+}
+
+func (q *DurableQueue[T]) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, durableQueueExt))
+}
+
+// Persists the job, then adds it to the queue.
+func (q *DurableQueue[T]) Push(value T) error {
+	data, err := q.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.unlock()
+
+	seq := q.nextSeq
+	q.nextSeq++
+
+	tmp := q.path(seq) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, q.path(seq)); err != nil {
+		return err
+	}
+
+	q.enqueue(&Job[T]{Value: value, seq: seq, queue: q}, false)
+	return nil
+}
+
+// Returns a task that is resolved with the next job,
+// once one is available. The job is leased to the caller
+// until it is acknowledged or the lease expires.
+// Cancelling the task gives up the claim on the next job.
+func (q *DurableQueue[T]) Pop() Task[*Job[T]] {
+	q.mu.Lock()
+	defer q.unlock()
+
+	task := newTask[*Job[T]]()
+	for len(q.ready) > 0 {
+		job := q.ready[0]
+		q.ready = q.ready[1:]
+		if q.handOut(task, job) {
+			return task
+		}
+	}
+	q.waiting = append(q.waiting, task)
+	return task
+}
+
+// Returns the number of jobs waiting in the queue,
+// and the number of jobs currently leased.
+func (q *DurableQueue[T]) Len() (ready, leased int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ready), len(q.leased)
+}
+
+// Gives the job to a waiting Pop(), or puts it
+// back in the queue. Retried jobs go to the front.
+// Must be called with mu held.
+func (q *DurableQueue[T]) enqueue(job *Job[T], retry bool) {
+	for len(q.waiting) > 0 {
+		task := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		if q.handOut(task, job) {
+			return
+		}
+	}
+	if retry {
+		q.ready = append([]*Job[T]{job}, q.ready...)
+	} else {
+		q.ready = append(q.ready, job)
+	}
+}
+
+// Must be called with mu held.
+func (q *DurableQueue[T]) handOut(task *taskImpl[*Job[T]], job *Job[T]) bool {
+	callbacks, ok := task.resolveDeferred(job)
+	if !ok {
+		return false
+	}
+	q.callbacks = append(q.callbacks, callbacks...)
+	q.leased[job.seq] = job
+	job.timer = time.AfterFunc(q.lease, func() { job.Nack() })
+	return true
+}
+
+// Unlocks mu, then runs the callbacks of the tasks
+// resolved meanwhile, since they may use the queue.
+func (q *DurableQueue[T]) unlock() {
+	callbacks := q.callbacks
+	q.callbacks = nil
+	q.mu.Unlock()
+	runCallbacks(callbacks)
+}
+
+// Marks the job as done, removing it from the queue for good.
+// No effect if the lease has expired and the job went
+// back to the queue.
+func (job *Job[T]) Ack() error {
+	q := job.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.leased[job.seq] != job {
+		return nil
+	}
+	job.timer.Stop()
+	delete(q.leased, job.seq)
+	return os.Remove(q.path(job.seq))
+}
+
+// Gives the job back to the queue, so that it is
+// handed out again.
+func (job *Job[T]) Nack() {
+	q := job.queue
+	q.mu.Lock()
+	defer q.unlock()
+
+	if q.leased[job.seq] != job {
+		return
+	}
+	job.timer.Stop()
+	delete(q.leased, job.seq)
+
+	retried := &Job[T]{Value: job.Value, seq: job.seq, queue: q}
+	q.enqueue(retried, true)
+}
@@ -0,0 +1,70 @@
+package quest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestDurableQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := quest.OpenDurableQueue(dir, quest.JSONCodec[string](), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pop := q.Pop()
+	pop.OnResolve(func() {
+		// the queue must not be locked while callbacks run
+		if ready, leased := q.Len(); ready != 0 || leased != 1 {
+			t.Errorf("unexpected lengths: %v ready, %v leased", ready, leased)
+		}
+	})
+	q.Push("a")
+	q.Push("b")
+	q.Push("c")
+
+	a, _ := pop.Await()
+	if a.Value != "a" {
+		t.Errorf("expected a, got %v", a.Value)
+	}
+	a.Ack()
+
+	b, _ := q.Pop().Await()
+	b.Nack()
+	b2, _ := q.Pop().Await()
+	if b2.Value != "b" {
+		t.Errorf("nacked job should be handed out again, got %v", b2.Value)
+	}
+	b.Ack()
+	if ready, leased := q.Len(); ready != 1 || leased != 1 {
+		t.Errorf("stale ack should have no effect, got %v ready, %v leased", ready, leased)
+	}
+
+	// restart while b is still leased
+	q, err = quest.OpenDurableQueue(dir, quest.JSONCodec[string](), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready, _ := q.Len(); ready != 2 {
+		t.Errorf("expected 2 jobs after restart, got %v", ready)
+	}
+	if job, _ := q.Pop().Await(); job.Value != "b" {
+		t.Errorf("expected b, got %v", job.Value)
+	}
+}
+
+func TestDurableQueueLease(t *testing.T) {
+	q, err := quest.OpenDurableQueue(t.TempDir(), quest.JSONCodec[int](), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Push(1)
+
+	job, _ := q.Pop().Await()
+	again, _ := q.Pop().Await()
+	if again.Value != job.Value {
+		t.Error("expired lease should return the job to the queue")
+	}
+}
@@ -0,0 +1,79 @@
+package quest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// The error returned by DynamicTask.Resolve() when the
+// value is not of the task's type.
+var ErrWrongType = errors.New("value of the wrong type")
+
+// A non-generic view of a Task[T], for host programs that
+// exchange tasks with plugins that don't share the generic
+// types. Created with ToDynamic(), converted back with
+// FromDynamic().
+type DynamicTask interface {
+	ID() int64
+	Name() string
+
+	// The result type T of the underlying Task[T].
+	Type() reflect.Type
+
+	// Same as Task.Await(), with the result as any.
+	Await() (result any, valid bool)
+
+	// Same as Task.Resolve(), but fails with ErrWrongType
+	// if the value is not of the type returned by Type().
+	Resolve(result any) error
+
+	Cancel()
+	Fail(error)
+	Error() error
+	IsDone() bool
+	IsCancelled() bool
+	Status() Status
+}
+
+// Returns a non-generic view of the task.
+// Example:
+//
+//	plugin.Run(ToDynamic(NewTask[Config]()))
+func ToDynamic[T any](task Task[T]) DynamicTask {
+	return dynamicTask[T]{task}
+}
+
+// Returns the Task[T] behind a DynamicTask.
+// ok is false if the task is not of type T.
+// Example:
+//
+//	config, ok := FromDynamic[Config](plugin.Result())
+func FromDynamic[T any](task DynamicTask) (result Task[T], ok bool) {
+	d, ok := task.(dynamicTask[T])
+	if !ok {
+		return nil, false
+	}
+	return d.Task, true
+}
+
+type dynamicTask[T any] struct {
+	Task[T]
+}
+
+func (d dynamicTask[T]) Type() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (d dynamicTask[T]) Await() (any, bool) {
+	return d.Task.Await()
+}
+
+func (d dynamicTask[T]) Resolve(value any) error {
+	v, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("%w: %T, expected %v", ErrWrongType, value, d.Type())
+	}
+	d.Task.Resolve(v)
+	return nil
+}
@@ -0,0 +1,34 @@
+package quest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestDynamicTask(t *testing.T) {
+	task := quest.NewTask[int]()
+	d := quest.ToDynamic(task)
+
+	if d.Type() != reflect.TypeOf(0) {
+		t.Errorf("unexpected type: %v", d.Type())
+	}
+	if err := d.Resolve("one"); !errors.Is(err, quest.ErrWrongType) {
+		t.Errorf("expected a type error, got %v", err)
+	}
+	if err := d.Resolve(1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if v, ok := d.Await(); !ok || v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	if _, ok := quest.FromDynamic[string](d); ok {
+		t.Error("conversion to the wrong type should fail")
+	}
+	if back, ok := quest.FromDynamic[int](d); !ok || back != task {
+		t.Error("expected the original task")
+	}
+}
@@ -0,0 +1,59 @@
+package quest
+
+// Returns the awaitable with its result type erased,
+// so awaitables of different types can be stored together,
+// e.g. in a plugin registry. Use Downcast() to recover
+// the original type.
+// Example:
+//
+//	pending := []Awaitable[any]{Erase(loadTexture), Erase(loadSound)}
+func Erase[T any](a Awaitable[T]) Awaitable[any] {
+	return erased[T]{a}
+}
+
+// Recovers the type of an awaitable returned by Erase().
+// ok is false if it was erased from another type.
+// Other awaitables are checked when awaited instead:
+// a result of the wrong type is returned as not OK.
+// Example:
+//
+//	if texture, ok := Downcast[Texture](pending[0]); ok {
+//	  t, _ := texture.Await()
+//	}
+func Downcast[T any](a Awaitable[any]) (result Awaitable[T], ok bool) {
+	if e, isErased := a.(interface{ unerase() any }); isErased {
+		result, ok = e.unerase().(Awaitable[T])
+		return result, ok
+	}
+	return downcast[T]{a}, true
+}
+
+type erased[T any] struct {
+	a Awaitable[T]
+}
+
+func (e erased[T]) Await() (any, bool) {
+	return e.a.Await()
+}
+
+func (e erased[T]) Error() error {
+	return errorOf(e.a)
+}
+
+func (e erased[T]) unerase() any {
+	return e.a
+}
+
+type downcast[T any] struct {
+	a Awaitable[any]
+}
+
+func (d downcast[T]) Await() (T, bool) {
+	value, ok := d.a.Await()
+	result, isT := value.(T)
+	return result, ok && isT
+}
+
+func (d downcast[T]) Error() error {
+	return errorOf(d.a)
+}
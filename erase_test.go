@@ -0,0 +1,36 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestErase(t *testing.T) {
+	n := quest.NewTask[int]()
+	s := quest.NewTask[string]()
+	n.Resolve(1)
+	s.Resolve("one")
+
+	all := []quest.Awaitable[any]{quest.Erase[int](n), quest.Erase[string](s)}
+	if v, ok := all[1].Await(); !ok || v != "one" {
+		t.Errorf("unexpected erased result: %v", v)
+	}
+
+	if _, ok := quest.Downcast[string](all[0]); ok {
+		t.Error("downcast to the wrong type should fail")
+	}
+	back, ok := quest.Downcast[int](all[0])
+	if !ok {
+		t.Fatal("downcast to the original type should succeed")
+	}
+	if v, _ := back.Await(); v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	var fn quest.AwaitableFn[any] = func() (any, bool) { return "two", true }
+	checked, _ := quest.Downcast[int](fn)
+	if _, ok := checked.Await(); ok {
+		t.Error("result of the wrong type should not be OK")
+	}
+}
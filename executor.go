@@ -0,0 +1,557 @@
+package quest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The error set by Fail() on tasks submitted to an
+// executor that is shutting down, see Drain() and Kill().
+var ErrShutdown = errors.New("executor shut down")
+
+// The error set by Fail() on tasks that didn't fit in
+// the queue of an executor, see WithOverflowPolicy().
+// It wraps ErrRejected.
+var ErrQueueFull = fmt.Errorf("executor queue full: %w", ErrRejected)
+
+// An Executor runs functions on a fixed set of worker
+// goroutines, taking them from a queue in FIFO order.
+// Unlike a Bulkhead, the workers are long-lived, which
+// suits a steady flow of small jobs.
+// Functions are submitted with Submit().
+// Example:
+//
+//	exec := NewExecutor(4, 1000)
+//	thumb := Submit(exec, func() Image { return resize(img) })
+type Executor struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	workers   int
+	busy      int
+	maxQueued int
+	queue     []executorJob
+	blocked   []executorJob
+	idle      *taskImpl[Void]
+	closed    bool
+	running   int
+	stopped   *taskImpl[Void]
+
+	overflow    OverflowPolicy
+	panicPolicy PanicPolicy
+	panicHook   func(value any, stack []byte)
+
+	completed  int
+	rejected   int
+	latencies  []time.Duration
+	nextSample int
+	// busy and worker time, accumulated up to lastChange.
+	created    time.Time
+	lastChange time.Time
+	busyTime   time.Duration
+	workerTime time.Duration
+}
+
+type executorJob struct {
+	task     executorTask
+	run      func()
+	queuedAt time.Time
+}
+
+type executorTask interface {
+	IsDone() bool
+	Fail(error)
+}
+
+// Options for NewExecutor().
+type ExecutorOption func(*Executor)
+
+// What an executor does with a function submitted
+// while its queue is full.
+type OverflowPolicy int
+
+const (
+	// Fails the task of the new function with ErrQueueFull.
+	OverflowReject OverflowPolicy = iota
+	// Keeps the task of the new function pending until
+	// there is room in the queue. Cancelling the task
+	// meanwhile withdraws the function.
+	OverflowBlock
+	// Fails the task of the oldest queued function with
+	// ErrQueueFull, to make room for the new one.
+	OverflowDropOldest
+)
+
+// Sets what the executor does when its queue is full.
+// The default is OverflowReject.
+// Example:
+//
+//	// only the latest frames matter
+//	encoder := NewExecutor(1, 3, WithOverflowPolicy(OverflowDropOldest))
+func WithOverflowPolicy(policy OverflowPolicy) ExecutorOption {
+	return func(e *Executor) {
+		e.overflow = policy
+	}
+}
+
+// What an executor does when a function panics.
+type PanicPolicy int
+
+const (
+	// Lets the panic crash the process, as with Start().
+	PanicCrash PanicPolicy = iota
+	// Fails the task with a *PanicError, and keeps the
+	// worker running.
+	PanicFailTask
+	// Fails the task with a *PanicError, and replaces the
+	// worker with a new goroutine.
+	PanicRestartWorker
+)
+
+// The error set by Fail() on tasks of functions that
+// panicked, with the PanicFailTask and PanicRestartWorker
+// policies.
+type PanicError struct {
+	// The value given to panic().
+	Value any
+	// The stack of the worker when it panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Sets what the executor does when a function panics.
+// The default is PanicCrash.
+func WithPanicPolicy(policy PanicPolicy) ExecutorOption {
+	return func(e *Executor) {
+		e.panicPolicy = policy
+	}
+}
+
+// Calls fn with the recovered value and the stack when
+// a function panics, before applying the panic policy.
+// fn is called from the worker, and must not block.
+// Example:
+//
+//	exec := NewExecutor(4, 100,
+//	  WithPanicPolicy(PanicFailTask),
+//	  WithPanicHook(func(value any, stack []byte) {
+//	    log.Printf("job panicked: %v\n%s", value, stack)
+//	  }),
+//	)
+func WithPanicHook(fn func(value any, stack []byte)) ExecutorOption {
+	return func(e *Executor) {
+		e.panicHook = fn
+	}
+}
+
+// The number of recent queue latencies that
+// ExecutorStats percentiles are computed from.
+const executorLatencySamples = 1024
+
+// A snapshot of the state of an executor, returned by Stats().
+type ExecutorStats struct {
+	Workers int
+	// The number of functions waiting in the queue.
+	Queued int
+	// The number of functions waiting for room in
+	// the queue, with OverflowBlock.
+	Blocked int
+	// The number of functions being run by the workers.
+	InFlight int
+	// The number of functions that have returned.
+	Completed int
+	// The number of functions that failed with
+	// ErrQueueFull or ErrShutdown instead of being run.
+	Rejected int
+	// The fraction of worker time spent running
+	// functions since the executor was created.
+	Utilization float64
+	// The time functions waited in the queue before
+	// being run, over the last 1024 functions.
+	QueueLatencyP50 time.Duration
+	QueueLatencyP99 time.Duration
+}
+
+// Creates an executor with the given number of workers,
+// and at most maxQueued functions waiting.
+// With zero or less workers, there is one per CPU,
+// as given by runtime.GOMAXPROCS().
+// maxQueued must be at least 1, since functions are
+// always queued before a worker picks them up:
+// an executor with no room would reject everything.
+func NewExecutor(workers, maxQueued int, opts ...ExecutorOption) *Executor {
+	if maxQueued <= 0 {
+		panic("quest: NewExecutor called with maxQueued < 1")
+	}
+	now := time.Now()
+	e := &Executor{
+		created:    now,
+		lastChange: now,
+		workers:    workers,
+		maxQueued:  maxQueued,
+		idle:       newTask[Void](),
+		stopped:    newTask[Void](),
+	}
+	if e.workers <= 0 {
+		e.workers = runtime.GOMAXPROCS(0)
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.cond = sync.NewCond(&e.mu)
+	e.idle.resolve(None)
+	e.running = e.workers
+	for i := 0; i < e.workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+// Sets the number of workers to multiplier times the
+// number of CPUs, as given by runtime.GOMAXPROCS(),
+// with at least one worker. Overrides the number
+// given to NewExecutor().
+// Example:
+//
+//	// mostly waiting on I/O
+//	exec := NewExecutor(0, 1000, WithWorkersPerCPU(4))
+func WithWorkersPerCPU(multiplier float64) ExecutorOption {
+	return func(e *Executor) {
+		e.workers = int(multiplier * float64(runtime.GOMAXPROCS(0)))
+		if e.workers < 1 {
+			e.workers = 1
+		}
+	}
+}
+
+// Changes the number of workers, e.g. to follow the load
+// or a change of container limits. New workers start
+// right away. When shrinking, idle workers exit right
+// away, and busy ones once their function returns.
+// n is at least one. No effect once the executor
+// is shutting down.
+func (e *Executor) Resize(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.workers = n
+	if e.running < n {
+		e.account(time.Now())
+		for ; e.running < n; e.running++ {
+			go e.work()
+		}
+	}
+	e.cond.Broadcast()
+}
+
+// Same as Start(), but runs fn on a worker of the executor.
+// If the queue is full, the function is handled as set
+// with WithOverflowPolicy(), by default the task fails with
+// ErrQueueFull. The task fails with ErrShutdown if the
+// executor is shutting down.
+// Cancelling the task while it is queued skips fn.
+func Submit[T any](e *Executor, fn func() T) Task[T] {
+	task := newTask[T]()
+	e.submit(task, func() {
+		task.Resolve(fn())
+	})
+	return task
+}
+
+// Queues the job, or fails its task if it is rejected.
+func (e *Executor) submit(task executorTask, run func()) {
+	job := executorJob{task, run, time.Now()}
+
+	e.mu.Lock()
+	var dropped executorTask
+	var err error
+	switch {
+	case e.closed:
+		e.rejected++
+		dropped, err = task, ErrShutdown
+	case len(e.queue) < e.maxQueued:
+		e.enqueue(job)
+	case e.overflow == OverflowBlock:
+		e.blocked = append(e.blocked, job)
+	case e.overflow == OverflowDropOldest && len(e.queue) > 0:
+		e.rejected++
+		dropped, err = e.queue[0].task, ErrQueueFull
+		e.queue = e.queue[1:]
+		e.enqueue(job)
+	default:
+		e.rejected++
+		dropped, err = task, ErrQueueFull
+	}
+	e.mu.Unlock()
+
+	// Failed without the lock, since callbacks
+	// of the task may submit new jobs.
+	if dropped != nil {
+		dropped.Fail(err)
+	}
+}
+
+// Adds the job to the queue, and wakes up a worker.
+// Must be called with mu held.
+func (e *Executor) enqueue(job executorJob) {
+	e.queue = append(e.queue, job)
+	if e.idle.IsDone() {
+		e.idle = newTask[Void]()
+	}
+	e.cond.Signal()
+}
+
+// Moves the jobs waiting for room into the queue,
+// skipping the cancelled ones.
+// Must be called with mu held.
+func (e *Executor) unblock() {
+	for len(e.blocked) > 0 && len(e.queue) < e.maxQueued {
+		job := e.blocked[0]
+		e.blocked[0] = executorJob{}
+		e.blocked = e.blocked[1:]
+		if !job.task.IsDone() {
+			e.enqueue(job)
+		}
+	}
+}
+
+// Same as Submit(), but fn is called with ctx, carrying
+// the returned task, see ContextWithTask(). The task
+// starts with the metadata of the task carried by ctx,
+// if any, so that it flows through nested submits.
+// The task fails with ctx.Err() if ctx is done before
+// fn is run.
+// Example:
+//
+//	SubmitContext(exec, ctx, func(ctx context.Context) Image {
+//	  id := MetaFromContext(ctx, "request-id")
+//	  ...
+//	})
+func SubmitContext[T any](e *Executor, ctx context.Context, fn func(context.Context) T) Task[T] {
+	task := newTask[T]()
+	inheritMeta(ctx, task)
+	ctx = ContextWithTask[T](ctx, task)
+	e.submit(task, func() {
+		if err := ctx.Err(); err != nil {
+			task.Fail(err)
+			return
+		}
+		task.Resolve(fn(ctx))
+	})
+	return task
+}
+
+// Returns a task that is resolved once the queue is empty
+// and all workers are idle, e.g. to wait for all background
+// work to finish in tests or at the end of a frame.
+// The task is already resolved if the executor is idle;
+// after a new Submit(), Idle() returns a new task.
+func (e *Executor) Idle() VoidTask {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.idle
+}
+
+// Returns a snapshot of the queue, the workers, and
+// the counters of the executor, e.g. for capacity planning.
+func (e *Executor) Stats() ExecutorStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.account(time.Now())
+	stats := ExecutorStats{
+		Workers:   e.workers,
+		Queued:    len(e.queue),
+		Blocked:   len(e.blocked),
+		InFlight:  e.busy,
+		Completed: e.completed,
+		Rejected:  e.rejected,
+	}
+	if e.workerTime > 0 {
+		stats.Utilization = float64(e.busyTime) / float64(e.workerTime)
+	}
+	if len(e.latencies) > 0 {
+		sorted := append([]time.Duration(nil), e.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats.QueueLatencyP50 = sorted[len(sorted)*50/100]
+		stats.QueueLatencyP99 = sorted[len(sorted)*99/100]
+	}
+	return stats
+}
+
+// Adds the busy and worker time since the last change
+// of either count. Must be called with mu held, before
+// changing busy or workers.
+func (e *Executor) account(now time.Time) {
+	elapsed := now.Sub(e.lastChange)
+	e.busyTime += time.Duration(e.busy) * elapsed
+	e.workerTime += time.Duration(e.running) * elapsed
+	e.lastChange = now
+}
+
+// Keeps the queue latency of a job, overwriting the
+// oldest one once there are executorLatencySamples.
+// Must be called with mu held.
+func (e *Executor) sample(latency time.Duration) {
+	if len(e.latencies) < executorLatencySamples {
+		e.latencies = append(e.latencies, latency)
+		return
+	}
+	e.latencies[e.nextSample] = latency
+	e.nextSample = (e.nextSample + 1) % executorLatencySamples
+}
+
+// Stops accepting new functions, and returns a task that
+// is resolved once the queued functions have run and the
+// workers have exited. The task fails with ctx.Err() if
+// ctx is done first, in which case the work goes on:
+// use Kill() to drop the remaining queue.
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//	if _, ok := exec.Drain(ctx).Await(); !ok {
+//	  exec.Kill().Await()
+//	}
+func (e *Executor) Drain(ctx context.Context) VoidTask {
+	e.mu.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	stopped := e.stopped
+	e.mu.Unlock()
+
+	drained := newTask[Void]()
+	go func() {
+		result, abort := stopped.AwaitAbortable()
+		defer abort()
+		select {
+		case <-result:
+			drained.Resolve(None)
+		case <-ctx.Done():
+			drained.Fail(ctx.Err())
+		}
+	}()
+	return drained
+}
+
+// Stops accepting new functions, fails the queued ones
+// with ErrShutdown, and returns a task that is resolved
+// once the running functions have returned and the
+// workers have exited.
+func (e *Executor) Kill() VoidTask {
+	e.mu.Lock()
+	e.closed = true
+	queue := append(e.queue, e.blocked...)
+	e.queue = nil
+	e.blocked = nil
+	e.cond.Broadcast()
+	e.mu.Unlock()
+
+	for _, job := range queue {
+		job.task.Fail(ErrShutdown)
+	}
+	return e.stopped
+}
+
+func (e *Executor) work() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for {
+		for len(e.queue) == 0 && !e.closed && e.running <= e.workers {
+			e.cond.Wait()
+		}
+		if len(e.queue) == 0 || e.running > e.workers {
+			e.account(time.Now())
+			e.running--
+			if e.running == 0 {
+				stopped := e.stopped
+				e.mu.Unlock()
+				stopped.Resolve(None)
+				e.mu.Lock()
+			}
+			return
+		}
+		job := e.queue[0]
+		e.queue = e.queue[1:]
+		e.unblock()
+		restart := false
+		if !job.task.IsDone() {
+			restart = e.runJob(job)
+		}
+		if e.busy == 0 && len(e.queue) == 0 {
+			// Resolved without the lock, so that callbacks
+			// of the task can submit new jobs. It is replaced
+			// first, so that these jobs get a new task.
+			idle := e.idle
+			e.idle = newTask[Void]()
+			e.idle.resolve(None)
+			e.mu.Unlock()
+			idle.Resolve(None)
+			e.mu.Lock()
+		}
+		if restart {
+			go e.work()
+			return
+		}
+	}
+}
+
+// Runs the job, keeping the stats up to date.
+// Returns true if the worker must be replaced.
+// Must be called with mu held, which is released
+// while the job runs.
+func (e *Executor) runJob(job executorJob) (restart bool) {
+	now := time.Now()
+	e.account(now)
+	e.sample(now.Sub(job.queuedAt))
+	e.busy++
+	e.mu.Unlock()
+
+	panicked := e.call(job)
+
+	e.mu.Lock()
+	e.account(time.Now())
+	e.busy--
+	e.completed++
+	return panicked && e.panicPolicy == PanicRestartWorker
+}
+
+// Runs the job, applying the panic policy if it panics.
+func (e *Executor) call(job executorJob) (panicked bool) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+		stack := debug.Stack()
+		if e.panicHook != nil {
+			e.panicHook(value, stack)
+		}
+		if e.panicPolicy == PanicCrash {
+			// work() expects mu to be held when unwinding.
+			e.mu.Lock()
+			panic(value)
+		}
+		job.task.Fail(&PanicError{value, stack})
+		panicked = true
+	}()
+
+	job.run()
+	return false
+}
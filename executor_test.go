@@ -0,0 +1,283 @@
+package quest_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestExecutor(t *testing.T) {
+	e := quest.NewExecutor(2, 10)
+	if !e.Idle().IsDone() {
+		t.Error("new executor should be idle")
+	}
+
+	var done atomic.Int32
+	tasks := make([]quest.Task[int], 5)
+	for i := range tasks {
+		i := i
+		tasks[i] = quest.Submit(e, func() int {
+			randomSleep()
+			done.Add(1)
+			return i
+		})
+	}
+
+	e.Idle().Await()
+	if done.Load() != 5 {
+		t.Errorf("idle before all jobs finished: %v done", done.Load())
+	}
+	for i, r := range quest.AwaitAllSlice(tasks) {
+		if r.Value != i {
+			t.Errorf("unexpected result %v for job %v", r.Value, i)
+		}
+	}
+}
+
+func TestExecutorDrain(t *testing.T) {
+	e := quest.NewExecutor(1, 10)
+	block := quest.NewVoidTask()
+	first := quest.Submit(e, func() int { block.Await(); return 1 })
+	second := quest.Submit(e, func() int { return 2 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	drained := e.Drain(ctx)
+	if rejected := quest.Submit(e, func() int { return 3 }); rejected.Error() != quest.ErrShutdown {
+		t.Errorf("expected ErrShutdown after Drain, got %v", rejected.Error())
+	}
+	if _, ok := drained.Await(); ok || drained.Error() != context.DeadlineExceeded {
+		t.Errorf("expected the drain to time out, got %v", drained.Error())
+	}
+
+	block.Resolve(quest.None)
+	e.Drain(context.Background()).Await()
+	if a, _ := first.Await(); a != 1 {
+		t.Error("running job should finish")
+	}
+	if b, _ := second.Await(); b != 2 {
+		t.Error("queued job should run when draining")
+	}
+}
+
+func TestExecutorKill(t *testing.T) {
+	e := quest.NewExecutor(1, 10)
+	block := quest.NewVoidTask()
+	quest.Submit(e, func() int { block.Await(); return 1 })
+	queued := quest.Submit(e, func() int { return 2 })
+	for e.Idle().IsDone() {
+		time.Sleep(time.Millisecond)
+	}
+
+	stopped := e.Kill()
+	if queued.Error() != quest.ErrShutdown {
+		t.Errorf("queued job should fail with ErrShutdown, got %v", queued.Error())
+	}
+	block.Resolve(quest.None)
+	stopped.Await()
+}
+
+func TestExecutorStats(t *testing.T) {
+	e := quest.NewExecutor(1, 2)
+	block := quest.NewVoidTask()
+	quest.Submit(e, func() int { block.Await(); return 0 })
+	for e.Idle().IsDone() || e.Stats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	quest.Submit(e, func() int { return 1 })
+	quest.Submit(e, func() int { return 2 })
+	quest.Submit(e, func() int { return 3 })
+
+	stats := e.Stats()
+	if stats.Workers != 1 || stats.InFlight != 1 || stats.Queued != 2 || stats.Rejected != 1 {
+		t.Errorf("unexpected stats while busy: %+v", stats)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	block.Resolve(quest.None)
+	e.Idle().Await()
+
+	stats = e.Stats()
+	if stats.Completed != 3 || stats.InFlight != 0 || stats.Queued != 0 {
+		t.Errorf("unexpected stats when idle: %+v", stats)
+	}
+	if stats.Utilization <= 0 || stats.Utilization > 1 {
+		t.Errorf("utilization out of range: %v", stats.Utilization)
+	}
+	if stats.QueueLatencyP99 < 5*time.Millisecond || stats.QueueLatencyP50 > stats.QueueLatencyP99 {
+		t.Errorf("unexpected queue latencies: %v, %v", stats.QueueLatencyP50, stats.QueueLatencyP99)
+	}
+}
+
+func TestExecutorPanicPolicy(t *testing.T) {
+	for _, policy := range []quest.PanicPolicy{quest.PanicFailTask, quest.PanicRestartWorker} {
+		var hooked atomic.Value
+		e := quest.NewExecutor(1, 10,
+			quest.WithPanicPolicy(policy),
+			quest.WithPanicHook(func(value any, stack []byte) {
+				hooked.Store(value)
+			}),
+		)
+
+		bad := quest.Submit(e, func() int { panic("boom") })
+		good := quest.Submit(e, func() int { return 1 })
+
+		if _, ok := bad.Await(); ok {
+			t.Fatal("task of a panicking function should fail")
+		}
+		var panicErr *quest.PanicError
+		if !errors.As(bad.Error(), &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+			t.Errorf("expected a PanicError, got %v", bad.Error())
+		}
+		if hooked.Load() != "boom" {
+			t.Error("panic hook was not called")
+		}
+		if v, _ := good.Await(); v != 1 {
+			t.Errorf("worker should keep running jobs with policy %v", policy)
+		}
+		if stats := e.Stats(); stats.Workers != 1 {
+			t.Errorf("unexpected worker count %v", stats.Workers)
+		}
+		e.Drain(context.Background()).Await()
+	}
+}
+
+func TestSubmitContext(t *testing.T) {
+	e := quest.NewExecutor(2, 10)
+	req := quest.NewVoidTask()
+	req.SetMeta("request-id", 42)
+	ctx := quest.ContextWithTask(context.Background(), req)
+
+	outer := quest.SubmitContext(e, ctx, func(ctx context.Context) any {
+		inner := quest.SubmitContext(e, ctx, func(ctx context.Context) any {
+			return quest.MetaFromContext(ctx, "request-id")
+		})
+		v, _ := inner.Await()
+		return v
+	})
+	if v, _ := outer.Await(); v != 42 {
+		t.Errorf("metadata should flow through nested submits, got %v", v)
+	}
+	if outer.Meta("request-id") != 42 {
+		t.Error("submitted task should inherit the metadata")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	skipped := quest.SubmitContext(e, cancelled, func(ctx context.Context) int {
+		t.Error("fn should not run with a done context")
+		return 0
+	})
+	if _, ok := skipped.Await(); ok || skipped.Error() != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", skipped.Error())
+	}
+}
+
+func TestExecutorResize(t *testing.T) {
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic with no room in the queue")
+			}
+		}()
+		quest.NewExecutor(1, 0)
+	}()
+	if quest.NewExecutor(0, 1).Stats().Workers != runtime.GOMAXPROCS(0) {
+		t.Error("expected one worker per CPU")
+	}
+	if quest.NewExecutor(1, 1, quest.WithWorkersPerCPU(2)).Stats().Workers != 2*runtime.GOMAXPROCS(0) {
+		t.Error("expected two workers per CPU")
+	}
+
+	e := quest.NewExecutor(1, 10)
+	waitInFlight := func(n int) {
+		for i := 0; e.Stats().InFlight != n; i++ {
+			if i > 1000 {
+				t.Fatalf("expected %v functions in flight, got %+v", n, e.Stats())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	block := quest.NewVoidTask()
+	for i := 0; i < 3; i++ {
+		quest.Submit(e, func() int { block.Await(); return 0 })
+	}
+	waitInFlight(1)
+	e.Resize(3)
+	waitInFlight(3)
+
+	e.Resize(1)
+	block.Resolve(quest.None)
+	e.Idle().Await()
+
+	block = quest.NewVoidTask()
+	for i := 0; i < 3; i++ {
+		quest.Submit(e, func() int { block.Await(); return 0 })
+	}
+	waitInFlight(1)
+	time.Sleep(5 * time.Millisecond)
+	if stats := e.Stats(); stats.InFlight != 1 || stats.Workers != 1 {
+		t.Errorf("expected a single worker after shrinking, got %+v", stats)
+	}
+	block.Resolve(quest.None)
+	e.Drain(context.Background()).Await()
+}
+
+func TestExecutorOverflowPolicy(t *testing.T) {
+	submitAll := func(policy quest.OverflowPolicy) (*quest.Executor, quest.VoidTask, []quest.Task[int]) {
+		e := quest.NewExecutor(1, 1, quest.WithOverflowPolicy(policy))
+		block := quest.NewVoidTask()
+		quest.Submit(e, func() int { block.Await(); return 0 })
+		for e.Stats().InFlight == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		tasks := make([]quest.Task[int], 3)
+		for i := range tasks {
+			i := i
+			tasks[i] = quest.Submit(e, func() int { return i })
+		}
+		return e, block, tasks
+	}
+
+	e, block, tasks := submitAll(quest.OverflowReject)
+	for _, task := range tasks[1:] {
+		if !errors.Is(task.Error(), quest.ErrQueueFull) || !errors.Is(task.Error(), quest.ErrRejected) {
+			t.Errorf("expected ErrQueueFull, got %v", task.Error())
+		}
+	}
+	block.Resolve(quest.None)
+	if v, ok := tasks[0].Await(); !ok || v != 0 {
+		t.Error("queued function should run")
+	}
+
+	e, block, tasks = submitAll(quest.OverflowDropOldest)
+	for _, task := range tasks[:2] {
+		if task.Error() != quest.ErrQueueFull {
+			t.Errorf("expected the oldest to be dropped, got %v", task.Error())
+		}
+	}
+	block.Resolve(quest.None)
+	if v, ok := tasks[2].Await(); !ok || v != 2 {
+		t.Error("newest function should run")
+	}
+
+	e, block, tasks = submitAll(quest.OverflowBlock)
+	if stats := e.Stats(); stats.Queued != 1 || stats.Blocked != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	tasks[1].Cancel()
+	block.Resolve(quest.None)
+	e.Idle().Await()
+	if tasks[0].Error() != nil || tasks[2].Error() != nil || !tasks[1].IsCancelled() {
+		t.Error("blocked functions should run once there is room")
+	}
+	if stats := e.Stats(); stats.Completed != 3 || stats.Rejected != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,32 @@
+package quest
+
+// Returns a task that is resolved with the result of
+// primary. If primary is cancelled or failed, secondary
+// is called and its result is used instead.
+// secondary is never called if primary succeeds, or if
+// the returned task is already cancelled.
+// Example:
+//
+//	user := Fallback(cache.Get(id), func() Task[User] {
+//	  return db.Get(id)
+//	})
+func Fallback[T any](primary Awaitable[T], secondary func() Task[T]) Task[T] {
+	task := newTask[T]()
+	go func() {
+		if value, ok := primary.Await(); ok {
+			task.Resolve(value)
+			return
+		}
+		if task.IsDone() {
+			return
+		}
+
+		backup := secondary()
+		if value, ok := backup.Await(); ok {
+			task.Resolve(value)
+		} else {
+			task.Fail(backup.Error())
+		}
+	}()
+	return task
+}
@@ -0,0 +1,43 @@
+package quest_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestFallback(t *testing.T) {
+	primary := quest.NewTask[string]()
+	called := false
+	task := quest.Fallback[string](primary, func() quest.Task[string] {
+		called = true
+		return quest.Start(func() string { return "origin" })
+	})
+	primary.Resolve("cache")
+	if v, _ := task.Await(); v != "cache" || called {
+		t.Errorf("expected cache without fallback, got %v", v)
+	}
+
+	primary = quest.NewTask[string]()
+	task = quest.Fallback[string](primary, func() quest.Task[string] {
+		return quest.Start(func() string { return "origin" })
+	})
+	primary.Cancel()
+	if v, ok := task.Await(); v != "origin" || !ok {
+		t.Errorf("expected origin, got %v", v)
+	}
+
+	primary = quest.NewTask[string]()
+	var calls atomic.Int32
+	task = quest.Fallback[string](primary, func() quest.Task[string] {
+		calls.Add(1)
+		return quest.Start(func() string { return "origin" })
+	})
+	task.Cancel()
+	primary.Cancel()
+	randomSleep()
+	if calls.Load() != 0 {
+		t.Error("fallback should not be called once the task is cancelled")
+	}
+}
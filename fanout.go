@@ -0,0 +1,156 @@
+package quest
+
+import "sync"
+
+// Applies fn to every value of src using n goroutines,
+// and sends the results to the returned stream.
+// A value of n of zero or less is treated as 1.
+// The output stream is closed once src is closed and
+// drained, with the same error if src has one.
+// Closing the output stream stops the workers, but a worker
+// waiting for src only notices once its next value arrives,
+// which is then dropped.
+// Outputs are not necessarily in the same order as inputs.
+func FanOut[T, U any](src *Stream[T], n int, fn func(T) U) *Stream[U] {
+	return FanOutErr(src, n, func(value T) (U, error) {
+		return fn(value), nil
+	})
+}
+
+// Same as FanOut(), but fn can fail. The output stream is
+// closed with the first error returned by fn, which stops
+// the workers.
+// Example:
+//
+//	pages := FanOutErr(urls, 4, fetchPage)
+//	for {
+//	  page, ok := pages.Next().Await()
+//	  if !ok { break }
+//	  index(page)
+//	}
+//	if err := pages.Error(); err != nil { ... }
+func FanOutErr[T, U any](src *Stream[T], n int, fn func(T) (U, error)) *Stream[U] {
+	if n <= 0 {
+		n = 1
+	}
+	out := NewStream[U](n)
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				value, ok := src.Next().Await()
+				if !ok {
+					return
+				}
+				result, err := fn(value)
+				if err != nil {
+					out.CloseWithError(err)
+					return
+				}
+				if _, ok := out.Send(result).Await(); !ok {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		out.CloseWithError(src.Error())
+	}()
+
+	return out
+}
+
+// Merges the values of all streams into the returned stream.
+// The output stream is closed once all streams are closed
+// and drained. If a stream is closed with an error, the
+// output stream is closed with that error right away.
+// Closing the output stream stops forwarding.
+func FanIn[T any](streams ...*Stream[T]) *Stream[T] {
+	out := NewStream[T](len(streams))
+
+	var wg sync.WaitGroup
+	wg.Add(len(streams))
+	for _, src := range streams {
+		go func(src *Stream[T]) {
+			defer wg.Done()
+			for {
+				value, ok := src.Next().Await()
+				if !ok {
+					if err := src.Error(); err != nil {
+						out.CloseWithError(err)
+					}
+					return
+				}
+				if _, ok := out.Send(value).Await(); !ok {
+					return
+				}
+			}
+		}(src)
+	}
+
+	go func() {
+		wg.Wait()
+		out.Close()
+	}()
+
+	return out
+}
+
+// Same as FanOut(), but the outputs are sent in the same
+// order as the inputs. At most parallelism values are
+// processed or waiting for their turn at any time, so a slow
+// value holds back the following ones instead of letting
+// the results pile up. A parallelism of zero or less is
+// treated as 1.
+// Example:
+//
+//	thumbnails := MapStream(images, 8, resize)
+func MapStream[I, O any](src *Stream[I], parallelism int, fn func(I) O) *Stream[O] {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	out := NewStream[O](parallelism)
+	ordered := make(chan Task[O], parallelism)
+	slots := make(chan struct{}, parallelism)
+	stop := make(chan struct{})
+
+	go func() {
+		defer close(ordered)
+		for {
+			value, ok := src.Next().Await()
+			if !ok {
+				return
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			ordered <- Start(func() O { return fn(value) })
+		}
+	}()
+
+	go func() {
+		defer func() {
+			close(stop)
+			for range ordered {
+				<-slots
+			}
+		}()
+		for task := range ordered {
+			value, _ := task.Await()
+			<-slots
+			if _, ok := out.Send(value).Await(); !ok {
+				return
+			}
+		}
+		out.CloseWithError(src.Error())
+	}()
+
+	return out
+}
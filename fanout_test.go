@@ -0,0 +1,83 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestFanOutFanIn(t *testing.T) {
+	doubled := quest.FanOut(quest.StreamOf(1, 2, 3), 2, func(n int) int {
+		return n * 2
+	})
+	merged := quest.FanIn(doubled, quest.StreamOf(100))
+
+	sum := 0
+	for {
+		n, ok := merged.Next().Await()
+		if !ok {
+			break
+		}
+		sum += n
+	}
+	if sum != 112 {
+		t.Errorf("expected 112, got %v", sum)
+	}
+	if merged.Error() != nil {
+		t.Errorf("unexpected error: %v", merged.Error())
+	}
+}
+
+func TestFanOutError(t *testing.T) {
+	err := errors.New("nope")
+	src := quest.NewStream[int](1)
+	src.Send(1)
+	src.CloseWithError(err)
+
+	out := quest.FanOut(src, 2, func(n int) int { return n })
+	if n, _ := out.Next().Await(); n != 1 {
+		t.Errorf("expected 1, got %v", n)
+	}
+	if _, ok := out.Next().Await(); ok || out.Error() != err {
+		t.Errorf("expected the source error, got %v", out.Error())
+	}
+}
+
+func TestFanOutErr(t *testing.T) {
+	err := errors.New("odd")
+	out := quest.FanOutErr(quest.StreamOf(2, 3), 0, func(n int) (int, error) {
+		if n%2 == 1 {
+			return 0, err
+		}
+		return n, nil
+	})
+	if n, _ := out.Next().Await(); n != 2 {
+		t.Errorf("expected 2, got %v", n)
+	}
+	if _, ok := out.Next().Await(); ok || out.Error() != err {
+		t.Errorf("expected the error of fn, got %v", out.Error())
+	}
+}
+
+func TestMapStream(t *testing.T) {
+	src := quest.StreamOf(1, 2, 3, 4, 5, 6, 7, 8)
+	out := quest.MapStream(src, 3, func(n int) int {
+		randomSleep()
+		return n * 10
+	})
+
+	for i := 1; i <= 8; i++ {
+		if n, _ := out.Next().Await(); n != i*10 {
+			t.Errorf("expected %v, got %v", i*10, n)
+		}
+	}
+	if _, ok := out.Next().Await(); ok {
+		t.Error("output should be closed after the source")
+	}
+
+	serial := quest.MapStream(quest.StreamOf(1, 2), 0, func(n int) int { return n })
+	if n, _ := serial.Next().Await(); n != 1 {
+		t.Errorf("zero parallelism should process one at a time, got %v", n)
+	}
+}
@@ -0,0 +1,97 @@
+package quest
+
+import (
+	"context"
+	"sync"
+)
+
+// Returns an awaitable that calls fn with ctx each time
+// it is awaited, in the goroutine calling Await().
+// Await() returns false if fn returns an error, the error
+// can be retrieved with the Error() method of the awaitable,
+// which the combinators use to report failures.
+// Example:
+//
+//	user := Func(ctx, func(ctx context.Context) (User, error) {
+//	  return db.GetUser(ctx, id)
+//	})
+//	u, posts, err := Await2E(user, Func(ctx, fetchPosts))
+func Func[T any](ctx context.Context, fn func(context.Context) (T, error)) Awaitable[T] {
+	return &funcAwaitable[T]{ctx: ctx, fn: fn}
+}
+
+// Same as Func(), but fn is only called on the first Await().
+// Subsequent and concurrent calls to Await() wait for and
+// return the same result. If fn panics, every call to
+// Await() panics with the same value.
+func FuncOnce[T any](ctx context.Context, fn func(context.Context) (T, error)) Awaitable[T] {
+	return &funcAwaitable[T]{ctx: ctx, fn: fn, once: &sync.Once{}}
+}
+
+// Same as FuncOnce(), for functions without a context,
+// like the ones given to sync.OnceValues(): fn runs once,
+// in the goroutine of the first Await(), and all callers
+// share the result. Nothing is started before then.
+// Example:
+//
+//	config := FromOnce(loadConfig)
+//	// ...
+//	c, ok := config.Await()
+func FromOnce[T any](fn func() (T, error)) Awaitable[T] {
+	return FuncOnce(context.Background(), func(context.Context) (T, error) {
+		return fn()
+	})
+}
+
+type funcAwaitable[T any] struct {
+	ctx  context.Context
+	fn   func(context.Context) (T, error)
+	once *sync.Once
+
+	mu         sync.Mutex
+	value      T
+	err        error
+	panicked   bool
+	panicValue any
+}
+
+func (f *funcAwaitable[T]) Await() (T, bool) {
+	if f.once == nil {
+		value, err := f.fn(f.ctx)
+		f.mu.Lock()
+		f.err = err
+		f.mu.Unlock()
+		return value, err == nil
+	}
+
+	f.once.Do(func() {
+		returned := false
+		defer func() {
+			if !returned {
+				f.mu.Lock()
+				f.panicked = true
+				f.panicValue = recover()
+				f.mu.Unlock()
+			}
+		}()
+		value, err := f.fn(f.ctx)
+		f.mu.Lock()
+		f.value, f.err = value, err
+		f.mu.Unlock()
+		returned = true
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.panicked {
+		panic(f.panicValue)
+	}
+	return f.value, f.err == nil
+}
+
+// Returns the error of the last call to fn.
+func (f *funcAwaitable[T]) Error() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
@@ -0,0 +1,69 @@
+package quest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestFunc(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	count := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	f := quest.Func(ctx, count)
+	f.Await()
+	if n, _ := f.Await(); n != 2 {
+		t.Errorf("expected fn to be called on each Await, got %v", n)
+	}
+
+	calls = 0
+	once := quest.FuncOnce(ctx, count)
+	once.Await()
+	if n, _ := once.Await(); n != 1 {
+		t.Errorf("expected fn to be called once, got %v", n)
+	}
+
+	err := errors.New("nope")
+	failing := quest.Func(ctx, func(ctx context.Context) (string, error) {
+		return "", err
+	})
+	if joined := quest.AwaitAllErr(failing); !errors.Is(joined, err) {
+		t.Errorf("expected the error of fn, got %v", joined)
+	}
+}
+
+func TestFromOnce(t *testing.T) {
+	calls := 0
+	config := quest.FromOnce(func() (string, error) {
+		calls++
+		return "config", nil
+	})
+	if calls != 0 {
+		t.Error("fn should not run before Await")
+	}
+
+	quest.AwaitAll(config, config)
+	if v, ok := config.Await(); !ok || v != "config" || calls != 1 {
+		t.Errorf("expected a single call, got %v calls and %q", calls, v)
+	}
+
+	broken := quest.FromOnce(func() (string, error) {
+		panic("bad config")
+	})
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() {
+				if r := recover(); r != "bad config" {
+					t.Errorf("call %v: expected the panic of fn, got %v", i, r)
+				}
+			}()
+			broken.Await()
+		}()
+	}
+}
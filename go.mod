@@ -1,6 +1,6 @@
 module github.com/nvlled/quest
 
-go 1.18
+go 1.20
 
 require (
 	github.com/nvlled/mud v0.0.0-20221215073054-5b5b416ff158
@@ -0,0 +1,164 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// A Health runs named checks periodically, and aggregates
+// their results, e.g. for a /healthz handler.
+// Example:
+//
+//	health := NewHealth()
+//	health.AddCheck("db", 10*time.Second, db.Ping)
+//	health.AddCheck("queue", 30*time.Second, queue.Check)
+//	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+//	  if !health.Snapshot().Healthy {
+//	    w.WriteHeader(http.StatusServiceUnavailable)
+//	  }
+//	})
+type Health struct {
+	mu     sync.Mutex
+	checks []*healthCheck
+	// Keeps the changes of status in order.
+	publishMu sync.Mutex
+	status    Task[bool]
+	healthy   bool
+	known     bool
+	stopped   bool
+	stop      chan struct{}
+}
+
+type healthCheck struct {
+	fn     func() error
+	status CheckStatus
+}
+
+// The state of a check, as of its last run.
+type CheckStatus struct {
+	Name string
+	// False if the last run failed, or if there was none yet.
+	Healthy   bool
+	LastError error
+	LastRun   time.Time
+	// How long the last run took.
+	Latency time.Duration
+	// The number of failed runs since the last success.
+	ConsecutiveFailures int
+}
+
+// The state of all checks, returned by Snapshot().
+type HealthSnapshot struct {
+	// True if all checks passed on their last run.
+	Healthy bool
+	Checks  []CheckStatus
+}
+
+// Creates a new Health without checks.
+func NewHealth() *Health {
+	return &Health{
+		status: NewLatestTask[bool](),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Adds a check, run right away and then every interval
+// until Stop(). A check fails if fn returns an error.
+// Panics if interval is not positive.
+func (h *Health) AddCheck(name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		panic("quest: AddCheck called with a non-positive interval")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stopped {
+		return
+	}
+	check := &healthCheck{fn: fn, status: CheckStatus{Name: name}}
+	h.checks = append(h.checks, check)
+	h.known = false
+	go h.run(check, interval)
+}
+
+// Returns a task holding the overall health: true if all
+// checks passed on their last run. It is pending until
+// every check has run once, and AwaitNext() waits for the
+// next change of the overall health.
+func (h *Health) Status() Task[bool] {
+	return h.status
+}
+
+// Returns the current state of all checks,
+// in the order they were added.
+func (h *Health) Snapshot() HealthSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := HealthSnapshot{Healthy: true}
+	for _, check := range h.checks {
+		snapshot.Checks = append(snapshot.Checks, check.status)
+		snapshot.Healthy = snapshot.Healthy && check.status.Healthy
+	}
+	return snapshot
+}
+
+// Stops running the checks.
+func (h *Health) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.stopped {
+		h.stopped = true
+		close(h.stop)
+	}
+}
+
+func (h *Health) run(check *healthCheck, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		err := check.fn()
+		h.record(check, start, err)
+
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Health) record(check *healthCheck, start time.Time, err error) {
+	h.mu.Lock()
+
+	status := &check.status
+	status.LastRun = start
+	status.Latency = time.Since(start)
+	status.LastError = err
+	status.Healthy = err == nil
+	if err != nil {
+		status.ConsecutiveFailures++
+	} else {
+		status.ConsecutiveFailures = 0
+	}
+
+	healthy := true
+	for _, c := range h.checks {
+		if c.status.LastRun.IsZero() {
+			h.mu.Unlock()
+			return
+		}
+		healthy = healthy && c.status.Healthy
+	}
+	changed := !h.known || healthy != h.healthy
+	h.known, h.healthy = true, healthy
+	h.publishMu.Lock()
+	defer h.publishMu.Unlock()
+	h.mu.Unlock()
+
+	if changed {
+		h.status.Resolve(healthy)
+	}
+}
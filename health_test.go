@@ -0,0 +1,55 @@
+package quest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestHealth(t *testing.T) {
+	var failing atomic.Bool
+	err := errors.New("down")
+
+	health := quest.NewHealth()
+	defer health.Stop()
+	health.AddCheck("always", time.Millisecond, func() error { return nil })
+	health.AddCheck("flaky", time.Millisecond, func() error {
+		if failing.Load() {
+			return err
+		}
+		return nil
+	})
+
+	if healthy, _ := health.Status().Await(); !healthy {
+		t.Error("expected to be healthy")
+	}
+
+	failing.Store(true)
+	deadline := time.Now().Add(time.Second)
+	snapshot := health.Snapshot()
+	for snapshot.Checks[1].ConsecutiveFailures < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		snapshot = health.Snapshot()
+	}
+	if healthy, _ := health.Status().Value(); healthy {
+		t.Error("expected to become unhealthy")
+	}
+
+	flaky := snapshot.Checks[1]
+	if snapshot.Healthy || flaky.Name != "flaky" || flaky.LastError != err || flaky.ConsecutiveFailures < 2 {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	if !snapshot.Checks[0].Healthy {
+		t.Error("other checks should stay healthy")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic with a zero interval")
+		}
+	}()
+	health.AddCheck("busy", 0, func() error { return nil })
+}
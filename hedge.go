@@ -0,0 +1,80 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// Runs fn, and if it hasn't finished after the given
+// duration, runs it again in parallel, up to maxExtra
+// additional times. The task is resolved with the first
+// successful result, the other attempts are ignored.
+// When an attempt fails and no other attempt is running,
+// the next backup is started right away. The task fails
+// with the last error once all attempts have failed.
+// Example:
+//
+//	// start a second request if the first takes more than 50ms
+//	page := Hedge(fetchPage, 50*time.Millisecond, 1)
+func Hedge[T any](fn func() (T, error), after time.Duration, maxExtra int) Task[T] {
+	task := newTask[T]()
+
+	var mu sync.Mutex
+	launched := 0
+	pending := 0
+
+	var launch func() bool
+	attempt := func() {
+		value, err := fn()
+		if err == nil {
+			task.Resolve(value)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		pending--
+		if pending == 0 && !launch() {
+			task.Fail(err)
+		}
+	}
+	// Must be called with mu held.
+	launch = func() bool {
+		if launched > maxExtra || task.IsDone() {
+			return false
+		}
+		launched++
+		pending++
+		go attempt()
+		return true
+	}
+
+	mu.Lock()
+	launch()
+	mu.Unlock()
+
+	stop := make(chan struct{})
+	task.Defer(func() { close(stop) })
+
+	go func() {
+		timer := time.NewTimer(after)
+		defer timer.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-timer.C:
+			}
+
+			mu.Lock()
+			ok := launch()
+			mu.Unlock()
+			if !ok {
+				return
+			}
+			timer.Reset(after)
+		}
+	}()
+
+	return task
+}
@@ -0,0 +1,39 @@
+package quest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestHedge(t *testing.T) {
+	calls := atomic.Int32{}
+	task := quest.Hedge(func() (int, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			time.Sleep(time.Second)
+		}
+		return int(n), nil
+	}, 10*time.Millisecond, 2)
+
+	if n, ok := task.Await(); n != 2 || !ok {
+		t.Errorf("expected the backup attempt to win, got %v", n)
+	}
+
+	err := errors.New("nope")
+	calls.Store(0)
+	task = quest.Hedge(func() (int, error) {
+		calls.Add(1)
+		return 0, err
+	}, time.Second, 2)
+
+	if _, ok := task.Await(); ok || task.Error() != err {
+		t.Error("task should fail when all attempts fail")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %v", calls.Load())
+	}
+}
@@ -0,0 +1,90 @@
+package quest
+
+import "sync"
+
+// A Memo caches the task returned for each key, so that
+// fn is called at most once per key: callers asking for
+// the same key share the same in-flight or completed task.
+// Entries never expire, use Evict() to remove them,
+// e.g. to retry a failed key.
+type Memo[K comparable, T any] struct {
+	mu      sync.Mutex
+	fn      func(K) (T, error)
+	tasks   map[K]Task[T]
+	onEvict func(key K, task Task[T])
+}
+
+// Creates a new memo for fn.
+func NewMemo[K comparable, T any](fn func(K) (T, error)) *Memo[K, T] {
+	return &Memo[K, T]{
+		fn:    fn,
+		tasks: map[K]Task[T]{},
+	}
+}
+
+// Returns a function that calls fn at most once per key,
+// and returns the same task for the same key.
+// Shorthand for NewMemo(fn).Get.
+// Example:
+//
+//	loadImage := Memoize(func(path string) (*Image, error) { ... })
+//	img1 := loadImage("a.png")
+//	img2 := loadImage("a.png") // same task as img1
+func Memoize[K comparable, T any](fn func(K) (T, error)) func(K) Task[T] {
+	return NewMemo(fn).Get
+}
+
+// Returns the task for the key, starting fn
+// if there is none yet.
+// The task fails with the error returned by fn.
+func (m *Memo[K, T]) Get(key K) Task[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if task, ok := m.tasks[key]; ok {
+		return task
+	}
+
+	task := newTask[T]()
+	m.tasks[key] = task
+	go func() {
+		value, err := m.fn(key)
+		if err != nil {
+			task.Fail(err)
+		} else {
+			task.Resolve(value)
+		}
+	}()
+
+	return task
+}
+
+// Removes the task of the key, the next Get()
+// will call fn again.
+// The task itself is left untouched.
+func (m *Memo[K, T]) Evict(key K) {
+	m.mu.Lock()
+	task, ok := m.tasks[key]
+	delete(m.tasks, key)
+	onEvict := m.onEvict
+	m.mu.Unlock()
+
+	if ok && onEvict != nil {
+		onEvict(key, task)
+	}
+}
+
+// Sets a function to be called whenever
+// an entry is removed with Evict().
+func (m *Memo[K, T]) OnEvict(fn func(key K, task Task[T])) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvict = fn
+}
+
+// Returns the number of cached keys.
+func (m *Memo[K, T]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.tasks)
+}
@@ -0,0 +1,54 @@
+package quest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestMemoize(t *testing.T) {
+	calls := atomic.Int32{}
+	square := quest.Memoize(func(n int) (int, error) {
+		calls.Add(1)
+		return n * n, nil
+	})
+
+	if square(3) != square(3) {
+		t.Error("same key should return the same task")
+	}
+	if n, _ := square(3).Await(); n != 9 {
+		t.Errorf("expected 9, got %v", n)
+	}
+	square(4).Await()
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %v", calls.Load())
+	}
+}
+
+func TestMemoEvict(t *testing.T) {
+	attempts := 0
+	memo := quest.NewMemo(func(key string) (string, error) {
+		attempts++
+		if attempts == 1 {
+			return "", errors.New("nope")
+		}
+		return key, nil
+	})
+	evicted := ""
+	memo.OnEvict(func(key string, task quest.Task[string]) {
+		evicted = key
+	})
+
+	if _, ok := memo.Get("a").Await(); ok {
+		t.Error("first attempt should fail")
+	}
+	memo.Evict("a")
+	if evicted != "a" || memo.Len() != 0 {
+		t.Error("key should be evicted")
+	}
+	if v, ok := memo.Get("a").Await(); v != "a" || !ok {
+		t.Errorf("expected a, got %v", v)
+	}
+}
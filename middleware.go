@@ -0,0 +1,119 @@
+package quest
+
+// Intercepts the calls made on a task returned by Wrap().
+// Each method receives next, which calls the following
+// middleware or the task itself. Not calling next
+// blocks the operation, e.g. to reject invalid values.
+// Embed PassThrough[T] to only override some methods.
+type TaskMiddleware[T any] interface {
+	Resolve(value T, next func(T))
+	Cancel(next func())
+	Fail(err error, next func(error))
+	Await(next func() (T, bool)) (T, bool)
+}
+
+// A TaskMiddleware that calls next for everything.
+// Meant to be embedded in other middlewares.
+type PassThrough[T any] struct{}
+
+func (PassThrough[T]) Resolve(value T, next func(T))         { next(value) }
+func (PassThrough[T]) Cancel(next func())                    { next() }
+func (PassThrough[T]) Fail(err error, next func(error))      { next(err) }
+func (PassThrough[T]) Await(next func() (T, bool)) (T, bool) { return next() }
+
+// Returns a view of the task where Resolve(), Cancel(),
+// Fail() and Await() (and their Try variants) go through
+// the middlewares first, in the given order.
+// ResolveWith() calls fn before the middlewares, unless
+// the task is already done.
+// Example:
+//
+//	type logResolve struct{ PassThrough[int] }
+//
+//	func (logResolve) Resolve(value int, next func(int)) {
+//	  log.Println("resolved with", value)
+//	  next(value)
+//	}
+//
+//	task := Wrap[int](NewTask[int](), logResolve{})
+func Wrap[T any](task Task[T], middlewares ...TaskMiddleware[T]) Task[T] {
+	return &wrappedTask[T]{Task: task, middlewares: middlewares}
+}
+
+type wrappedTask[T any] struct {
+	Task[T]
+	middlewares []TaskMiddleware[T]
+}
+
+func (w *wrappedTask[T]) Resolve(value T) {
+	w.resolve(0, value, w.Task.Resolve)
+}
+
+func (w *wrappedTask[T]) TryResolve(value T) bool {
+	ok := false
+	w.resolve(0, value, func(value T) { ok = w.Task.TryResolve(value) })
+	return ok
+}
+
+func (w *wrappedTask[T]) ResolveWith(fn func() T) bool {
+	if !w.canResolve() {
+		return false
+	}
+	return w.TryResolve(fn())
+}
+
+func (w *wrappedTask[T]) canResolve() bool {
+	if task, ok := w.Task.(interface{ canResolve() bool }); ok {
+		return task.canResolve()
+	}
+	return !w.Task.IsDone()
+}
+
+func (w *wrappedTask[T]) resolve(i int, value T, last func(T)) {
+	if i == len(w.middlewares) {
+		last(value)
+		return
+	}
+	w.middlewares[i].Resolve(value, func(value T) { w.resolve(i+1, value, last) })
+}
+
+func (w *wrappedTask[T]) Cancel() {
+	w.cancel(0, w.Task.Cancel)
+}
+
+func (w *wrappedTask[T]) TryCancel() bool {
+	ok := false
+	w.cancel(0, func() { ok = w.Task.TryCancel() })
+	return ok
+}
+
+func (w *wrappedTask[T]) cancel(i int, last func()) {
+	if i == len(w.middlewares) {
+		last()
+		return
+	}
+	w.middlewares[i].Cancel(func() { w.cancel(i+1, last) })
+}
+
+func (w *wrappedTask[T]) Fail(err error) {
+	w.fail(0, err)
+}
+
+func (w *wrappedTask[T]) fail(i int, err error) {
+	if i == len(w.middlewares) {
+		w.Task.Fail(err)
+		return
+	}
+	w.middlewares[i].Fail(err, func(err error) { w.fail(i+1, err) })
+}
+
+func (w *wrappedTask[T]) Await() (T, bool) {
+	return w.await(0)
+}
+
+func (w *wrappedTask[T]) await(i int) (T, bool) {
+	if i == len(w.middlewares) {
+		return w.Task.Await()
+	}
+	return w.middlewares[i].Await(func() (T, bool) { return w.await(i + 1) })
+}
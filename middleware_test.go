@@ -0,0 +1,47 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+type rejectNegative struct{ quest.PassThrough[int] }
+
+func (rejectNegative) Resolve(value int, next func(int)) {
+	if value >= 0 {
+		next(value)
+	}
+}
+
+type doubleAwait struct{ quest.PassThrough[int] }
+
+func (doubleAwait) Await(next func() (int, bool)) (int, bool) {
+	value, ok := next()
+	return value * 2, ok
+}
+
+func TestWrap(t *testing.T) {
+	task := quest.Wrap[int](quest.NewTask[int](), rejectNegative{}, doubleAwait{})
+
+	if task.TryResolve(-1) {
+		t.Error("negative value should be rejected")
+	}
+	if task.IsDone() {
+		t.Error("task should still be pending")
+	}
+
+	task.Resolve(21)
+	if n, _ := task.Await(); n != 42 {
+		t.Errorf("expected 42, got %v", n)
+	}
+
+	called := false
+	if task.ResolveWith(func() int { called = true; return 1 }) || called {
+		t.Error("ResolveWith should not compute a value for a done task")
+	}
+	pending := quest.Wrap[int](quest.NewTask[int](), rejectNegative{})
+	if !pending.ResolveWith(func() int { return 1 }) {
+		t.Error("ResolveWith should resolve a pending task")
+	}
+}
@@ -0,0 +1,123 @@
+package quest
+
+import "sync"
+
+// An MVar is a cell that is either empty or holds
+// exactly one value. Put() waits until the cell is empty,
+// Take() waits until the cell is full.
+// Useful for handing off values between producers
+// and consumers exactly once.
+// The zero value is an empty MVar ready for use.
+type MVar[T any] struct {
+	mu    sync.Mutex
+	value T
+	full  bool
+
+	putters []mvarPut[T]
+	takers  []*taskImpl[T]
+	// Callbacks of the tasks resolved with mu held,
+	// run by unlock().
+	callbacks []func()
+}
+
+type mvarPut[T any] struct {
+	value T
+	task  *taskImpl[Void]
+}
+
+// Creates a new empty MVar.
+func NewMVar[T any]() *MVar[T] {
+	return &MVar[T]{}
+}
+
+// Puts a value into the MVar.
+// The returned task is resolved once the value
+// has been stored, which may require waiting for
+// a Take() if the MVar is currently full.
+// Cancelling the returned task before it resolves
+// withdraws the value.
+func (mv *MVar[T]) Put(value T) VoidTask {
+	mv.mu.Lock()
+	defer mv.unlock()
+
+	task := newTask[Void]()
+
+	if mv.full {
+		mv.putters = append(mv.putters, mvarPut[T]{value, task})
+		return task
+	}
+
+	if !mv.handOff(value) {
+		mv.value = value
+		mv.full = true
+	}
+	task.resolve(None)
+
+	return task
+}
+
+// Takes the value out of the MVar, leaving it empty.
+// The returned task is resolved once a value
+// is available.
+// Cancelling the returned task before it resolves
+// gives up the claim on the next value.
+func (mv *MVar[T]) Take() Task[T] {
+	mv.mu.Lock()
+	defer mv.unlock()
+
+	task := newTask[T]()
+
+	if !mv.full {
+		mv.takers = append(mv.takers, task)
+		return task
+	}
+
+	var empty T
+	task.resolve(mv.value)
+	mv.value = empty
+	mv.full = false
+
+	for len(mv.putters) > 0 {
+		put := mv.putters[0]
+		mv.putters = mv.putters[1:]
+		if callbacks, ok := put.task.resolveDeferred(None); ok {
+			mv.callbacks = append(mv.callbacks, callbacks...)
+			mv.value = put.value
+			mv.full = true
+			break
+		}
+	}
+
+	return task
+}
+
+// Returns true if the MVar currently holds a value.
+func (mv *MVar[T]) IsFull() bool {
+	mv.mu.Lock()
+	defer mv.mu.Unlock()
+	return mv.full
+}
+
+// Gives the value directly to a waiting taker.
+// Takers that have been cancelled are skipped.
+// Must be called with mu held.
+func (mv *MVar[T]) handOff(value T) bool {
+	for len(mv.takers) > 0 {
+		taker := mv.takers[0]
+		mv.takers = mv.takers[1:]
+		if callbacks, ok := taker.resolveDeferred(value); ok {
+			mv.callbacks = append(mv.callbacks, callbacks...)
+			return true
+		}
+	}
+	return false
+}
+
+// Unlocks mu, then runs the callbacks of the tasks
+// resolved meanwhile, since they may use the MVar.
+func (mv *MVar[T]) unlock() {
+	callbacks := mv.callbacks
+	mv.callbacks = nil
+	mv.mu.Unlock()
+	runCallbacks(callbacks)
+}
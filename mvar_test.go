@@ -0,0 +1,58 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestMVar(t *testing.T) {
+	mv := quest.NewMVar[int]()
+
+	t1 := mv.Put(1)
+	t2 := mv.Put(2)
+	if !t1.IsDone() {
+		t.Error("first put should not block")
+	}
+	if t2.IsDone() {
+		t.Error("second put should block while full")
+	}
+
+	if v, _ := mv.Take().Await(); v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+	if _, ok := t2.Await(); !ok {
+		t.Error("second put should resolve after take")
+	}
+	if v, _ := mv.Take().Await(); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+
+	taker := mv.Take()
+	if taker.IsDone() {
+		t.Error("take should block while empty")
+	}
+	// the MVar must not be locked while callbacks run
+	full := quest.NewTask[bool]()
+	taker.OnResolve(func() { full.Resolve(mv.IsFull()) })
+	go mv.Put(3)
+	if v, ok := taker.Await(); v != 3 || !ok {
+		t.Errorf("expected 3, got %v", v)
+	}
+	if isFull, _ := full.Await(); isFull {
+		t.Error("value should be handed off directly")
+	}
+}
+
+func TestMVarCancelledTaker(t *testing.T) {
+	mv := quest.NewMVar[string]()
+
+	cancelled := mv.Take()
+	waiting := mv.Take()
+	cancelled.Cancel()
+
+	mv.Put("apples")
+	if v, ok := waiting.Await(); v != "apples" || !ok {
+		t.Errorf("expected apples, got %v", v)
+	}
+}
@@ -0,0 +1,182 @@
+package quest
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/nvlled/mud"
+)
+
+// Options for task construction.
+// Example:
+//
+//	NewTask[int](WithStickyError(), WithStickyPanic())
+//	NewTask[Config](WithName("config"), WithDefaultValue(defaultConfig))
+type TaskOption func(*taskOptions)
+
+type taskOptions struct {
+	name         string
+	stickyError  bool
+	stickyPanic  bool
+	checkpointer Checkpointer
+	checkpointFn func(name string, err error)
+	timing       bool
+	deadline     time.Time
+	defaultValue any
+	autoReset    bool
+	pool         *mud.Pool
+	cancelErr    error
+	stackTrace   bool
+}
+
+// Names the task, mostly used for debugging.
+// The name can be retrieved with Name().
+func WithName(name string) TaskOption {
+	return func(opts *taskOptions) {
+		opts.name = name
+	}
+}
+
+// Keeps the last error across Reset(), so that Error()
+// still returns it while the task is retried.
+// The error is only replaced by a subsequent Fail().
+func WithStickyError() TaskOption {
+	return func(opts *taskOptions) {
+		opts.stickyError = true
+	}
+}
+
+// Keeps the SetPanic() setting across Reset().
+func WithStickyPanic() TaskOption {
+	return func(opts *taskOptions) {
+		opts.stickyPanic = true
+	}
+}
+
+// Persists the resolved values of the task with the
+// checkpointer, under the task's name given with WithName().
+// If a value was saved by a previous run, the task is
+// created already resolved with that value.
+// Has no effect on tasks without a name.
+func WithCheckpointer(cp Checkpointer) TaskOption {
+	return func(opts *taskOptions) {
+		opts.checkpointer = cp
+	}
+}
+
+// Calls fn with the errors of WithCheckpointer(), when
+// a checkpoint can't be loaded, decoded, encoded or saved.
+// Without it, such errors are ignored: the task is left
+// pending, or its value is computed again on the next run.
+// Example:
+//
+//	NewTask[Report](WithName("report"), WithCheckpointer(cp),
+//	  WithCheckpointErrorHook(func(name string, err error) {
+//	    log.Printf("checkpoint %s: %v", name, err)
+//	  }))
+func WithCheckpointErrorHook(fn func(name string, err error)) TaskOption {
+	return func(opts *taskOptions) {
+		opts.checkpointFn = fn
+	}
+}
+
+// Records when the task is created, reset and settled,
+// see Timestamps() and Duration().
+func WithTiming() TaskOption {
+	return func(opts *taskOptions) {
+		opts.timing = true
+	}
+}
+
+// Fails the task with ErrTimeout if it is not settled
+// by the deadline. Only applies to the first cycle:
+// the deadline is dropped once the task is settled.
+// See also WithDeadline(), to add a deadline to
+// an existing task.
+func WithTaskDeadline(deadline time.Time) TaskOption {
+	return func(opts *taskOptions) {
+		opts.deadline = deadline
+	}
+}
+
+// Same as calling SetDefaultValue() after the construction.
+// The value must be assignable to the task's type, or be
+// a number that fits in it, e.g. WithDefaultValue(5) on a
+// Task[int64]. Real and complex numbers don't mix.
+// Otherwise the construction panics.
+func WithDefaultValue[T any](value T) TaskOption {
+	return func(opts *taskOptions) {
+		opts.defaultValue = value
+	}
+}
+
+// Converts a value given to WithDefaultValue() to T.
+// Returns false if it's not assignable, or a number that
+// doesn't convert to T without loss.
+func convertDefault[T any](value any) (T, bool) {
+	if result, ok := value.(T); ok {
+		return result, true
+	}
+	var result T
+	target := reflect.TypeOf(&result).Elem()
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(target) {
+		reflect.ValueOf(&result).Elem().Set(v)
+		return result, true
+	}
+	if !isNumber(v.Kind()) || !isNumber(target.Kind()) ||
+		isComplex(v.Kind()) != isComplex(target.Kind()) || !v.Type().ConvertibleTo(target) {
+		return result, false
+	}
+	converted := v.Convert(target)
+	if converted.Convert(v.Type()).Interface() != value {
+		return result, false
+	}
+	reflect.ValueOf(&result).Elem().Set(converted)
+	return result, true
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Complex128
+}
+
+func isComplex(kind reflect.Kind) bool {
+	return kind == reflect.Complex64 || kind == reflect.Complex128
+}
+
+// Makes the task behave like the ones created
+// with NewMultiTask().
+func WithAutoReset() TaskOption {
+	return func(opts *taskOptions) {
+		opts.autoReset = true
+	}
+}
+
+// Allocates the task from the pool, like AllocTaskIn().
+// Free the task afterwards with FreeTaskIn().
+func WithPool(pool *mud.Pool) TaskOption {
+	return func(opts *taskOptions) {
+		opts.pool = pool
+	}
+}
+
+// Same as calling SetCancelError() after the construction.
+func WithCancelError(err error) TaskOption {
+	return func(opts *taskOptions) {
+		opts.cancelErr = err
+	}
+}
+
+// Makes Fail() wrap the error in a TaskError, holding
+// the stack of the caller and the identity of the task,
+// for post-mortem debugging.
+// Example:
+//
+//	if leaf, ok := LeafTaskError(task.Error()); ok && leaf.Stack != nil {
+//	  log.Printf("%v\n%s", leaf, leaf.Stack)
+//	}
+func WithStackTrace() TaskOption {
+	return func(opts *taskOptions) {
+		opts.stackTrace = true
+	}
+}
@@ -0,0 +1,169 @@
+package quest
+
+import "sync"
+
+// A Pipeline runs a chain of stages connected by
+// bounded streams. Each stage reads values from the
+// stream of the previous stage, and sends its results
+// to its own output stream.
+// Stages are added with Stage(), and started with Run().
+// Example:
+//
+//	p := NewPipeline(16)
+//	pages := Stage(p, StreamOf(urls...), 8, fetch)
+//	docs := Stage(p, pages, 2, parse)
+//	done := p.Run()
+//	for {
+//	  doc, ok := docs.Next().Await()
+//	  if !ok { break }
+//	  index(doc)
+//	}
+//	if _, ok := done.Await(); !ok {
+//	  log.Println(done.Error())
+//	}
+type Pipeline struct {
+	mu      sync.Mutex
+	buffer  int
+	started bool
+	active  int
+	stages  []func()
+	streams []interface{ CloseWithError(error) }
+	stop    chan struct{}
+	done    *taskImpl[Void]
+}
+
+// Creates a new pipeline, where the stream between
+// each stage buffers up to the given number of values.
+func NewPipeline(buffer int) *Pipeline {
+	p := &Pipeline{
+		buffer: buffer,
+		stop:   make(chan struct{}),
+		done:   newTask[Void](),
+	}
+	p.done.OnCancel(func() {
+		p.stopAll(p.done.Error())
+	})
+	return p
+}
+
+// Adds a stage that applies fn to every value of in,
+// using the given number of goroutines, at least one.
+// Returns the output stream, which is closed when in
+// is closed and all values have been processed.
+// Outputs are not necessarily in the same order as inputs.
+// If fn returns an error, the whole pipeline fails
+// with that error.
+func Stage[I, O any](p *Pipeline, in *Stream[I], parallelism int, fn func(I) (O, error)) *Stream[O] {
+	out := NewStream[O](p.buffer)
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	start := func() {
+		var wg sync.WaitGroup
+		wg.Add(parallelism)
+		for i := 0; i < parallelism; i++ {
+			go func() {
+				defer wg.Done()
+				runStage(p, in, out, fn)
+			}()
+		}
+		go func() {
+			wg.Wait()
+			if err := in.Error(); err != nil {
+				p.done.Fail(err)
+			}
+			out.Close()
+			p.stageDone()
+		}()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.streams = append(p.streams, out)
+	p.active++
+	if p.started {
+		start()
+	} else {
+		p.stages = append(p.stages, start)
+	}
+
+	return out
+}
+
+func runStage[I, O any](p *Pipeline, in *Stream[I], out *Stream[O], fn func(I) (O, error)) {
+	for {
+		next := in.Next()
+		result, abort := next.AwaitAbortable()
+
+		var r Result[I]
+		select {
+		case r = <-result:
+		case <-p.stop:
+			abort()
+			next.Cancel()
+			return
+		}
+		if !r.OK {
+			return
+		}
+
+		value, err := fn(r.Value)
+		if err != nil {
+			p.done.Fail(err)
+			return
+		}
+		if _, ok := out.Send(value).Await(); !ok {
+			return
+		}
+	}
+}
+
+// Starts all stages, and returns a task that is resolved
+// once every stage has finished. The task fails with
+// the first error returned by a stage, in which case the
+// remaining stages are stopped and their output streams
+// are closed with that error.
+// Cancelling the task stops the pipeline.
+// Calling Run() again returns the same task.
+func (p *Pipeline) Run() VoidTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.started {
+		p.started = true
+		for _, start := range p.stages {
+			start()
+		}
+		p.stages = nil
+		if p.active == 0 {
+			p.done.Resolve(None)
+		}
+	}
+
+	return p.done
+}
+
+func (p *Pipeline) stageDone() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active--
+	if p.active == 0 {
+		p.done.Resolve(None)
+	}
+}
+
+func (p *Pipeline) stopAll(err error) {
+	p.mu.Lock()
+	streams := p.streams
+	p.mu.Unlock()
+
+	close(p.stop)
+	if err == nil {
+		err = ErrCancelled
+	}
+	for _, s := range streams {
+		s.CloseWithError(err)
+	}
+}
@@ -0,0 +1,79 @@
+package quest_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestPipeline(t *testing.T) {
+	p := quest.NewPipeline(2)
+	numbers := quest.StreamOf(1, 2, 3, 4, 5)
+	squares := quest.Stage(p, numbers, 3, func(n int) (int, error) {
+		return n * n, nil
+	})
+	strs := quest.Stage(p, squares, 2, func(n int) (string, error) {
+		return strconv.Itoa(n), nil
+	})
+	done := p.Run()
+
+	sum := 0
+	for {
+		s, ok := strs.Next().Await()
+		if !ok {
+			break
+		}
+		n, _ := strconv.Atoi(s)
+		sum += n
+	}
+
+	if _, ok := done.Await(); !ok {
+		t.Errorf("pipeline failed: %v", done.Error())
+	}
+	if sum != 55 {
+		t.Errorf("expected 55, got %v", sum)
+	}
+}
+
+func TestStageParallelism(t *testing.T) {
+	for _, parallelism := range []int{0, -1} {
+		p := quest.NewPipeline(1)
+		doubles := quest.Stage(p, quest.StreamOf(1, 2, 3), parallelism, func(n int) (int, error) {
+			return n * 2, nil
+		})
+		p.Run()
+
+		sum := 0
+		for {
+			n, ok := doubles.Next().Await()
+			if !ok {
+				break
+			}
+			sum += n
+		}
+		if sum != 12 {
+			t.Errorf("parallelism %v: expected 12, got %v", parallelism, sum)
+		}
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	err := errors.New("nope")
+	p := quest.NewPipeline(0)
+	// never closed, the failure must still stop the stage
+	source := quest.NewStream[int](0)
+	source.Send(1)
+	out := quest.Stage(p, source, 1, func(n int) (int, error) {
+		return 0, err
+	})
+	done := p.Run()
+
+	if _, ok := done.Await(); ok || done.Error() != err {
+		t.Errorf("expected the stage error, got %v", done.Error())
+	}
+	if _, ok := out.Next().Await(); ok {
+		t.Error("output stream should be closed")
+	}
+}
@@ -0,0 +1,82 @@
+package quest
+
+import (
+	"errors"
+	"time"
+)
+
+// The error set by Fail() on tasks returned by PollUntil()
+// when the probe is still not ready after the last attempt.
+var ErrMaxAttempts = errors.New("max attempts reached")
+
+// Options for PollUntil().
+type PollOption func(*pollOptions)
+
+type pollOptions struct {
+	backoff     Backoff
+	maxAttempts int
+}
+
+// Spaces out the attempts with the backoff,
+// instead of the fixed interval.
+func WithPollBackoff(backoff Backoff) PollOption {
+	return func(opts *pollOptions) {
+		opts.backoff = backoff
+	}
+}
+
+// Gives up after n attempts, failing with ErrMaxAttempts.
+func WithMaxAttempts(n int) PollOption {
+	return func(opts *pollOptions) {
+		opts.maxAttempts = n
+	}
+}
+
+// Calls probe every interval until it reports that it is
+// ready, and resolves the task with the value it returned.
+// The first call is made right away. The task fails with
+// the error returned by probe, if any.
+// Cancelling the task stops the polling.
+// Example:
+//
+//	db := PollUntil(time.Second, func() (*sql.DB, bool, error) {
+//	  conn, err := sql.Open("postgres", dsn)
+//	  if err != nil { return nil, false, err }
+//	  return conn, conn.Ping() == nil, nil
+//	}, WithMaxAttempts(30))
+func PollUntil[T any](interval time.Duration, probe func() (T, bool, error), opts ...PollOption) Task[T] {
+	options := pollOptions{backoff: ConstantBackoff(interval)}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	task := newTask[T]()
+	go func() {
+		cancelled, abort := task.AwaitAbortable()
+		defer abort()
+
+		for attempt := 1; ; attempt++ {
+			value, ready, err := probe()
+			switch {
+			case err != nil:
+				task.Fail(err)
+				return
+			case ready:
+				task.Resolve(value)
+				return
+			case options.maxAttempts > 0 && attempt >= options.maxAttempts:
+				task.Fail(ErrMaxAttempts)
+				return
+			}
+
+			timer := time.NewTimer(options.backoff.Next(attempt))
+			select {
+			case <-cancelled:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return task
+}
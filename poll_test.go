@@ -0,0 +1,35 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestPollUntil(t *testing.T) {
+	attempts := 0
+	ready := quest.PollUntil(time.Millisecond, func() (int, bool, error) {
+		attempts++
+		return attempts, attempts == 3, nil
+	})
+	if n, ok := ready.Await(); !ok || n != 3 {
+		t.Errorf("expected to be ready on the third attempt, got %v", n)
+	}
+
+	never := quest.PollUntil(0, func() (int, bool, error) {
+		return 0, false, nil
+	}, quest.WithMaxAttempts(5), quest.WithPollBackoff(quest.ConstantBackoff(time.Millisecond)))
+	if _, ok := never.Await(); ok || never.Error() != quest.ErrMaxAttempts {
+		t.Errorf("expected to give up, got %v", never.Error())
+	}
+
+	err := errors.New("nope")
+	failing := quest.PollUntil(time.Millisecond, func() (int, bool, error) {
+		return 0, false, err
+	})
+	if _, ok := failing.Await(); ok || failing.Error() != err {
+		t.Errorf("expected the probe error, got %v", failing.Error())
+	}
+}
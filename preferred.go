@@ -0,0 +1,86 @@
+package quest
+
+import "time"
+
+// Returns a task that is resolved with the result of one
+// of the tasks, preferring earlier tasks over later ones.
+// When the first result arrives, it waits up to window for
+// the tasks listed before it to also complete, and picks
+// the earliest listed one that did.
+// It doesn't wait once all earlier tasks have settled.
+// The task fails if none of the tasks are resolved,
+// with the error of the last one that failed.
+// Example:
+//
+//	// take the cache result only if the database is slow
+//	user := AnyPreferred(20*time.Millisecond, db.Get(id), cache.Get(id))
+func AnyPreferred[T any](window time.Duration, tasks ...Awaitable[T]) Task[T] {
+	task := newTask[T]()
+
+	type indexed struct {
+		index  int
+		result Result[T]
+	}
+	results := make(chan indexed, len(tasks))
+	aborts := make([]func(), len(tasks))
+	for i, t := range tasks {
+		ch, abort := awaitChan(t)
+		aborts[i] = abort
+		go func(i int) {
+			if r, ok := <-ch; ok {
+				results <- indexed{i, r}
+			}
+		}(i)
+	}
+
+	go func() {
+		settled := make([]bool, len(tasks))
+		var best *indexed
+		var lastErr error
+		var deadline <-chan time.Time
+
+		// Checks if all tasks listed before the best are settled.
+		isBest := func() bool {
+			for i := 0; i < best.index; i++ {
+				if !settled[i] {
+					return false
+				}
+			}
+			return true
+		}
+
+	loop:
+		for range tasks {
+			select {
+			case r := <-results:
+				settled[r.index] = true
+				if !r.result.OK {
+					lastErr = withIdentity(tasks[r.index], errorOf(tasks[r.index]))
+				} else if best == nil || r.index < best.index {
+					best = &r
+					if deadline == nil {
+						timer := time.NewTimer(window)
+						defer timer.Stop()
+						deadline = timer.C
+					}
+				}
+				if best != nil && isBest() {
+					break loop
+				}
+			case <-deadline:
+				break loop
+			}
+		}
+
+		for _, abort := range aborts {
+			abort()
+		}
+		if best != nil {
+			task.Resolve(best.result.Value)
+		} else {
+			task.Fail(lastErr)
+		}
+	}()
+
+	return task
+}
@@ -0,0 +1,43 @@
+package quest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestAnyPreferred(t *testing.T) {
+	slow := quest.NewTask[string]()
+	fast := quest.NewTask[string]()
+	fast.Resolve("cache")
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		slow.Resolve("db")
+	}()
+
+	if v, _ := quest.AnyPreferred[string](time.Second, slow, fast).Await(); v != "db" {
+		t.Errorf("expected the preferred result, got %v", v)
+	}
+
+	tooSlow := quest.NewTask[string]()
+	if v, _ := quest.AnyPreferred[string](5*time.Millisecond, tooSlow, fast).Await(); v != "cache" {
+		t.Errorf("expected the fallback result after the window, got %v", v)
+	}
+
+	failed := quest.NewTask[string]()
+	failed.Cancel()
+	start := time.Now()
+	if v, _ := quest.AnyPreferred[string](time.Second, failed, fast).Await(); v != "cache" {
+		t.Errorf("expected cache, got %v", v)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("should not wait when earlier tasks have already failed")
+	}
+
+	all := quest.AnyPreferred[string](time.Second, failed, tooSlow)
+	tooSlow.Cancel()
+	if _, ok := all.Await(); ok {
+		t.Error("should fail when no task is resolved")
+	}
+}
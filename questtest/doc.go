@@ -0,0 +1,3 @@
+// This package provides helpers to test code that
+// consumes quest tasks, without real concurrency.
+package questtest
@@ -0,0 +1,217 @@
+package questtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+// A call made on a MockTask.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// A Task that can be scripted to behave in a given way
+// when awaited, and that records the calls made on it.
+// The methods that are not scripted behave like
+// a regular task.
+type MockTask[T any] struct {
+	quest.Task[T]
+
+	mu    sync.Mutex
+	calls []Call
+
+	awaits    int
+	cycle     int
+	deliverAt int
+	value     T
+	blocked   chan struct{}
+	failCycle int
+	failErr   error
+}
+
+// Creates a new mock task. Without scripting,
+// it behaves like quest.NewTask().
+// Example:
+//
+//	task := questtest.NewMockTask[int]()
+//	task.DeliverAfter(3, 42)
+//	consumer.Poll(task) // Await() returns false twice,
+//	                    // then 42 on the third call
+func NewMockTask[T any]() *MockTask[T] {
+	return &MockTask[T]{Task: quest.NewTask[T](), cycle: 1}
+}
+
+// Makes the nth call to Await() resolve the task with
+// the value. Previous calls return right away with the
+// zero value and false, instead of blocking.
+func (m *MockTask[T]) DeliverAfter(n int, value T) *MockTask[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliverAt = n
+	m.value = value
+	return m
+}
+
+// Makes Await(), AwaitNext() and AwaitAbortable() block
+// until Unblock(), Cancel() or Reset() is called,
+// e.g. to test timeouts.
+func (m *MockTask[T]) BlockForever() *MockTask[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.blocked == nil {
+		m.blocked = make(chan struct{})
+	}
+	return m
+}
+
+// Releases the calls blocked by BlockForever(), which
+// then behave as if the task was not blocked.
+func (m *MockTask[T]) Unblock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.blocked != nil {
+		close(m.blocked)
+		m.blocked = nil
+	}
+}
+
+// Makes the task fail with err when awaited on the nth
+// cycle. Cycles are counted from 1, and incremented by
+// each successful Reset().
+func (m *MockTask[T]) FailOnCycle(n int, err error) *MockTask[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failCycle = n
+	m.failErr = err
+	return m
+}
+
+// Returns the calls made so far, in order.
+func (m *MockTask[T]) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Returns the number of calls made to the method.
+func (m *MockTask[T]) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, call := range m.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *MockTask[T]) record(method string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{method, args})
+}
+
+func (m *MockTask[T]) Await() (T, bool) {
+	m.record("Await")
+	return m.await(m.Task.Await)
+}
+
+func (m *MockTask[T]) AwaitNext() (T, bool) {
+	m.record("AwaitNext")
+	return m.await(m.Task.AwaitNext)
+}
+
+func (m *MockTask[T]) AwaitWithTicker(interval time.Duration, onTick func(time.Duration)) (T, bool) {
+	m.record("AwaitWithTicker", interval)
+	return m.await(func() (T, bool) {
+		return m.Task.AwaitWithTicker(interval, onTick)
+	})
+}
+
+func (m *MockTask[T]) AwaitAbortable() (<-chan quest.Result[T], func()) {
+	m.record("AwaitAbortable")
+
+	result := make(chan quest.Result[T], 1)
+	aborted := make(chan struct{})
+	var once sync.Once
+	go func() {
+		defer close(result)
+		value, ok := m.await(m.Task.Await)
+		select {
+		case result <- quest.Result[T]{Value: value, OK: ok}:
+		case <-aborted:
+		}
+	}()
+
+	return result, func() {
+		once.Do(func() { close(aborted) })
+	}
+}
+
+func (m *MockTask[T]) await(next func() (T, bool)) (T, bool) {
+	m.mu.Lock()
+	m.awaits++
+	if blocked := m.blocked; blocked != nil {
+		m.mu.Unlock()
+		<-blocked
+		m.mu.Lock()
+	}
+	if m.failCycle == m.cycle {
+		m.Task.Fail(m.failErr)
+	}
+	if m.deliverAt > 0 {
+		if m.awaits < m.deliverAt {
+			m.mu.Unlock()
+			var empty T
+			return empty, false
+		}
+		m.Task.TryResolve(m.value)
+	}
+	m.mu.Unlock()
+
+	return next()
+}
+
+func (m *MockTask[T]) Resolve(value T) {
+	m.record("Resolve", value)
+	m.Task.Resolve(value)
+}
+
+func (m *MockTask[T]) TryResolve(value T) bool {
+	m.record("TryResolve", value)
+	return m.Task.TryResolve(value)
+}
+
+func (m *MockTask[T]) Cancel() {
+	m.record("Cancel")
+	m.Task.Cancel()
+	m.Unblock()
+}
+
+func (m *MockTask[T]) TryCancel() bool {
+	m.record("TryCancel")
+	return m.Task.TryCancel()
+}
+
+func (m *MockTask[T]) Fail(err error) {
+	m.record("Fail", err)
+	m.Task.Fail(err)
+}
+
+func (m *MockTask[T]) Reset() bool {
+	m.record("Reset")
+	m.Unblock()
+	ok := m.Task.Reset()
+	if ok {
+		m.mu.Lock()
+		m.cycle++
+		m.awaits = 0
+		m.mu.Unlock()
+	}
+	return ok
+}
@@ -0,0 +1,70 @@
+package questtest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+	"github.com/nvlled/quest/questtest"
+)
+
+func TestDeliverAfter(t *testing.T) {
+	task := questtest.NewMockTask[int]().DeliverAfter(3, 42)
+
+	for i := 0; i < 2; i++ {
+		if _, ok := task.Await(); ok {
+			t.Error("value should not be delivered yet")
+		}
+	}
+	if n, ok := task.Await(); !ok || n != 42 {
+		t.Errorf("expected 42 on the third await, got %v", n)
+	}
+	if task.CallCount("Await") != 3 {
+		t.Errorf("expected 3 recorded awaits, got %v", task.CallCount("Await"))
+	}
+}
+
+func TestFailOnCycle(t *testing.T) {
+	err := errors.New("nope")
+	task := questtest.NewMockTask[int]().FailOnCycle(2, err)
+
+	task.Resolve(1)
+	if _, ok := task.Await(); !ok {
+		t.Error("first cycle should succeed")
+	}
+
+	task.Reset()
+	go task.Resolve(2)
+	if _, ok := task.Await(); ok || task.Error() != err {
+		t.Errorf("second cycle should fail, got %v", task.Error())
+	}
+
+	calls := task.Calls()
+	if calls[0].Method != "Resolve" || calls[0].Args[0] != 1 {
+		t.Errorf("unexpected first call: %+v", calls[0])
+	}
+}
+
+func TestBlockForever(t *testing.T) {
+	task := questtest.NewMockTask[int]().BlockForever()
+	task.Resolve(1)
+
+	var awaitable quest.Awaitable[int] = task
+	deadline := quest.WithDeadline(awaitable, time.Now().Add(10*time.Millisecond))
+	if _, ok := deadline.Await(); ok || deadline.Error() != quest.ErrTimeout {
+		t.Error("blocked task should time out")
+	}
+
+	task.Unblock()
+	if n, ok := task.Await(); n != 1 || !ok {
+		t.Errorf("unblocked task should return its value, got %v", n)
+	}
+
+	blocked := questtest.NewMockTask[int]().BlockForever()
+	result, _ := blocked.AwaitAbortable()
+	blocked.Cancel()
+	if r := <-result; r.OK {
+		t.Error("cancelling should release the blocked call")
+	}
+}
@@ -0,0 +1,160 @@
+package questtest
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+// A call recorded by a Recorder.
+type Event struct {
+	Method string
+	Args   []any
+	// The ID of the goroutine that made the call.
+	Goroutine uint64
+	Time      time.Time
+}
+
+// A Task that forwards every call to the wrapped task,
+// and records the calls to Resolve(), Cancel(), Fail(),
+// Reset() and the Await methods.
+type Recorder[T any] struct {
+	quest.Task[T]
+
+	mu     sync.Mutex
+	events []Event
+}
+
+// Wraps the task to record the calls made on it.
+// Example:
+//
+//	task := questtest.Record(quest.NewTask[int]())
+//	runSystem(task)
+//	task.AssertSequence(t, "Await", "Resolve")
+func Record[T any](task quest.Task[T]) *Recorder[T] {
+	return &Recorder[T]{Task: task}
+}
+
+// Returns the recorded calls, in order.
+func (r *Recorder[T]) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// Returns the names of the recorded methods, in order.
+func (r *Recorder[T]) Methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	methods := make([]string, len(r.events))
+	for i, event := range r.events {
+		methods[i] = event.Method
+	}
+	return methods
+}
+
+// Fails the test unless the recorded methods are
+// exactly the given ones, in the same order.
+func (r *Recorder[T]) AssertSequence(t testing.TB, methods ...string) {
+	t.Helper()
+	got := r.Methods()
+	if strings.Join(got, ",") != strings.Join(methods, ",") {
+		t.Errorf("expected calls %v, got %v", methods, got)
+	}
+}
+
+// Fails the test unless the method was called n times.
+func (r *Recorder[T]) AssertCalled(t testing.TB, method string, n int) {
+	t.Helper()
+	count := 0
+	for _, m := range r.Methods() {
+		if m == method {
+			count++
+		}
+	}
+	if count != n {
+		t.Errorf("expected %v to be called %v times, got %v", method, n, count)
+	}
+}
+
+func (r *Recorder[T]) record(method string, args ...any) {
+	event := Event{method, args, goroutineID(), time.Now()}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *Recorder[T]) Await() (T, bool) {
+	r.record("Await")
+	return r.Task.Await()
+}
+
+func (r *Recorder[T]) AwaitNext() (T, bool) {
+	r.record("AwaitNext")
+	return r.Task.AwaitNext()
+}
+
+func (r *Recorder[T]) AwaitWithTicker(interval time.Duration, onTick func(time.Duration)) (T, bool) {
+	r.record("AwaitWithTicker", interval)
+	return r.Task.AwaitWithTicker(interval, onTick)
+}
+
+func (r *Recorder[T]) AwaitAbortable() (<-chan quest.Result[T], func()) {
+	r.record("AwaitAbortable")
+	return r.Task.AwaitAbortable()
+}
+
+func (r *Recorder[T]) Resolve(value T) {
+	r.record("Resolve", value)
+	r.Task.Resolve(value)
+}
+
+func (r *Recorder[T]) TryResolve(value T) bool {
+	r.record("TryResolve", value)
+	return r.Task.TryResolve(value)
+}
+
+func (r *Recorder[T]) ResolveWith(fn func() T) bool {
+	r.record("ResolveWith")
+	return r.Task.ResolveWith(fn)
+}
+
+func (r *Recorder[T]) Cancel() {
+	r.record("Cancel")
+	r.Task.Cancel()
+}
+
+func (r *Recorder[T]) TryCancel() bool {
+	r.record("TryCancel")
+	return r.Task.TryCancel()
+}
+
+func (r *Recorder[T]) Fail(err error) {
+	r.record("Fail", err)
+	r.Task.Fail(err)
+}
+
+func (r *Recorder[T]) Reset() bool {
+	r.record("Reset")
+	return r.Task.Reset()
+}
+
+// Parses the goroutine ID from the header of the stack,
+// "goroutine 42 [running]:".
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
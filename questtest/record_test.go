@@ -0,0 +1,37 @@
+package questtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+	"github.com/nvlled/quest/questtest"
+)
+
+func TestRecord(t *testing.T) {
+	task := questtest.Record(quest.NewTask[int]())
+
+	done := make(chan struct{})
+	go func() {
+		task.Await()
+		close(done)
+	}()
+	for task.WaiterCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	task.Resolve(1)
+	<-done
+	task.Reset()
+
+	task.AssertSequence(t, "Await", "Resolve", "Reset")
+	task.AssertCalled(t, "Resolve", 1)
+
+	events := task.Events()
+	if events[0].Goroutine == events[1].Goroutine || events[0].Goroutine == 0 {
+		t.Errorf("expected different goroutines, got %v and %v",
+			events[0].Goroutine, events[1].Goroutine)
+	}
+	if events[1].Args[0] != 1 || events[1].Time.Before(events[0].Time) {
+		t.Errorf("unexpected event: %+v", events[1])
+	}
+}
@@ -0,0 +1,85 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// A Refresher caches the value returned by a loader, and
+// reloads it in the background once it is older than the
+// ttl, while still serving the stale value (stale-while-
+// revalidate). Created with Refreshing().
+type Refresher[T any] struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	loader     func() (T, error)
+	current    Task[T]
+	loadedAt   time.Time
+	refreshing Task[T]
+}
+
+// Creates a refresher for the loader. Nothing is loaded
+// until the first Get().
+// Example:
+//
+//	token := Refreshing(50*time.Minute, fetchToken)
+//	t, ok := token.Get().Await()
+func Refreshing[T any](ttl time.Duration, loader func() (T, error)) *Refresher[T] {
+	return &Refresher[T]{ttl: ttl, loader: loader}
+}
+
+// Returns a resolved task with the cached value, and
+// starts a refresh if the value is older than the ttl.
+// Before the first successful load, returns the task of
+// the load in progress instead, which fails with the
+// error of the loader; the next Get() tries again.
+// Failed refreshes keep the previous value.
+func (r *Refresher[T]) Get() Task[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil {
+		return r.refresh()
+	}
+	if time.Since(r.loadedAt) > r.ttl {
+		r.refresh()
+	}
+	return r.current
+}
+
+// Starts a refresh, even if the value is not stale,
+// and returns its task. If a refresh is already in
+// progress, returns that one instead.
+func (r *Refresher[T]) ForceRefresh() Task[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.refresh()
+}
+
+// Must be called with mu held.
+func (r *Refresher[T]) refresh() Task[T] {
+	if r.refreshing != nil {
+		return r.refreshing
+	}
+
+	task := newTask[T]()
+	r.refreshing = task
+	go func() {
+		value, err := r.loader()
+
+		r.mu.Lock()
+		r.refreshing = nil
+		if err == nil {
+			r.current = task
+			r.loadedAt = time.Now()
+		}
+		r.mu.Unlock()
+
+		if err != nil {
+			task.Fail(err)
+		} else {
+			task.Resolve(value)
+		}
+	}()
+	return task
+}
@@ -0,0 +1,45 @@
+package quest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestRefreshing(t *testing.T) {
+	var version atomic.Int32
+	var failing atomic.Bool
+	r := quest.Refreshing(5*time.Millisecond, func() (int32, error) {
+		if failing.Load() {
+			return 0, errors.New("nope")
+		}
+		return version.Add(1), nil
+	})
+
+	if n, _ := r.Get().Await(); n != 1 {
+		t.Errorf("expected the first load, got %v", n)
+	}
+	if n, _ := r.Get().Await(); n != 1 {
+		t.Errorf("expected the cached value, got %v", n)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if n, _ := r.Get().Await(); n != 1 {
+		t.Errorf("stale value should be served while refreshing, got %v", n)
+	}
+	if n, _ := r.ForceRefresh().Await(); n < 2 {
+		t.Errorf("expected a refreshed value, got %v", n)
+	}
+
+	failing.Store(true)
+	before, _ := r.Get().Await()
+	if _, ok := r.ForceRefresh().Await(); ok {
+		t.Error("refresh should fail")
+	}
+	if n, _ := r.Get().Await(); n != before {
+		t.Errorf("failed refresh should keep the value, got %v", n)
+	}
+}
@@ -0,0 +1,193 @@
+package remote
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+)
+
+type message struct {
+	Op      string `json:"op"`
+	ID      string `json:"id"`
+	Payload []byte `json:"payload,omitempty"`
+}
+
+const (
+	opExpect   = "expect"
+	opUnexpect = "unexpect"
+	opDeliver  = "deliver"
+	// Answered with opPong, once the messages sent
+	// before it have been handled.
+	opPing = "ping"
+	opPong = "pong"
+)
+
+// A Broker accepts client connections, and forwards
+// delivered payloads to the clients expecting them.
+// Payloads delivered before anyone expects them are
+// kept until a client does.
+type Broker struct {
+	mu       sync.Mutex
+	listener net.Listener
+	payloads map[string][]byte
+	expects  map[string]map[*brokerConn]struct{}
+	conns    map[*brokerConn]struct{}
+	closed   bool
+}
+
+type brokerConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+}
+
+func (c *brokerConn) send(msg message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.enc.Encode(msg); err != nil {
+		c.conn.Close()
+	}
+}
+
+// Listens on the address, and serves clients in the background.
+// Example:
+//
+//	broker, err := remote.Listen("unix", "/tmp/app.sock")
+func Listen(network, address string) (*Broker, error) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewBroker(listener), nil
+}
+
+// Serves clients on the listener in the background.
+func NewBroker(listener net.Listener) *Broker {
+	b := &Broker{
+		listener: listener,
+		payloads: map[string][]byte{},
+		expects:  map[string]map[*brokerConn]struct{}{},
+		conns:    map[*brokerConn]struct{}{},
+	}
+	go b.serve()
+	return b
+}
+
+// Returns the address the broker is listening on.
+func (b *Broker) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+// Stops listening, and disconnects all clients.
+// Payloads that were not yet expected are discarded.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	conns := b.conns
+	b.conns = map[*brokerConn]struct{}{}
+	b.mu.Unlock()
+
+	err := b.listener.Close()
+	for c := range conns {
+		c.conn.Close()
+	}
+	return err
+}
+
+func (b *Broker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c := &brokerConn{conn: conn, enc: json.NewEncoder(conn)}
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			conn.Close()
+			return
+		}
+		b.conns[c] = struct{}{}
+		b.mu.Unlock()
+
+		go b.handle(c)
+	}
+}
+
+func (b *Broker) handle(c *brokerConn) {
+	defer b.disconnect(c)
+
+	dec := json.NewDecoder(c.conn)
+	for {
+		var msg message
+		if err := dec.Decode(&msg); err != nil {
+			return
+		}
+		switch msg.Op {
+		case opExpect:
+			b.expect(c, msg.ID)
+		case opUnexpect:
+			b.unexpect(c, msg.ID)
+		case opDeliver:
+			b.deliver(msg.ID, msg.Payload)
+		case opPing:
+			c.send(message{Op: opPong, ID: msg.ID})
+		}
+	}
+}
+
+func (b *Broker) expect(c *brokerConn, id string) {
+	b.mu.Lock()
+	payload, ok := b.payloads[id]
+	if ok {
+		delete(b.payloads, id)
+	} else {
+		if b.expects[id] == nil {
+			b.expects[id] = map[*brokerConn]struct{}{}
+		}
+		b.expects[id][c] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	if ok {
+		c.send(message{Op: opDeliver, ID: id, Payload: payload})
+	}
+}
+
+func (b *Broker) unexpect(c *brokerConn, id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.expects[id], c)
+	if len(b.expects[id]) == 0 {
+		delete(b.expects, id)
+	}
+}
+
+func (b *Broker) deliver(id string, payload []byte) {
+	b.mu.Lock()
+	conns := b.expects[id]
+	if len(conns) == 0 {
+		b.payloads[id] = payload
+	}
+	delete(b.expects, id)
+	b.mu.Unlock()
+
+	for c := range conns {
+		c.send(message{Op: opDeliver, ID: id, Payload: payload})
+	}
+}
+
+func (b *Broker) disconnect(c *brokerConn) {
+	c.conn.Close()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.conns, c)
+	for id, conns := range b.expects {
+		delete(conns, c)
+		if len(conns) == 0 {
+			delete(b.expects, id)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+// The error returned when using a client after Close().
+var ErrClosed = errors.New("remote client closed")
+
+// A Client connects to a Broker. It reconnects
+// automatically when the connection is lost, registering
+// its pending expectations again and sending the payloads
+// that couldn't be delivered in the meantime.
+type Client struct {
+	network string
+	address string
+	backoff quest.Backoff
+
+	mu      sync.Mutex
+	conn    net.Conn
+	enc     *json.Encoder
+	expects map[string][]quest.Task[[]byte]
+	outbox  []message
+	pings   map[string]quest.Task[quest.Void]
+	nextID  int
+	closed  bool
+}
+
+// Connects to the broker at the address.
+// Reconnection attempts are spaced out with exponential
+// backoff, up to 5 seconds between attempts.
+// Example:
+//
+//	client, err := remote.Dial("unix", "/tmp/app.sock")
+//	result, ok := client.Expect("job/42").Await()
+func Dial(network, address string) (*Client, error) {
+	return DialBackoff(network, address, quest.ExponentialBackoff(50*time.Millisecond, 5*time.Second))
+}
+
+// Same as Dial(), but with the given reconnection backoff.
+func DialBackoff(network, address string, backoff quest.Backoff) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		network: network,
+		address: address,
+		backoff: backoff,
+		expects: map[string][]quest.Task[[]byte]{},
+		pings:   map[string]quest.Task[quest.Void]{},
+	}
+	c.setConn(conn)
+
+	return c, nil
+}
+
+// Returns a task that is resolved with the payload
+// delivered for the id, by this or another process.
+// Cancelling the task stops expecting the payload,
+// and the broker gives it to the other clients.
+func (c *Client) Expect(id string) quest.Task[[]byte] {
+	task := quest.NewTask[[]byte]()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		task.Fail(ErrClosed)
+		return task
+	}
+
+	c.expects[id] = append(c.expects[id], task)
+	if len(c.expects[id]) == 1 {
+		c.send(message{Op: opExpect, ID: id})
+	}
+	task.OnCancel(func() { c.forget(id, task) })
+
+	return task
+}
+
+// Same as Expect(), but the task fails with
+// quest.ErrTimeout if the payload is not delivered in time.
+func (c *Client) ExpectTimeout(id string, timeout time.Duration) quest.Task[[]byte] {
+	task := c.Expect(id)
+	timer := time.AfterFunc(timeout, func() {
+		task.Fail(quest.ErrTimeout)
+	})
+	task.Defer(func() { timer.Stop() })
+	return task
+}
+
+// Delivers the payload for the id. If the client is
+// disconnected, the payload is sent once it reconnects.
+// Returns ErrClosed if the client is closed.
+func (c *Client) Deliver(id string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	msg := message{Op: opDeliver, ID: id, Payload: payload}
+	if !c.send(msg) {
+		c.outbox = append(c.outbox, msg)
+	}
+	return nil
+}
+
+// Returns a task that is resolved once the broker has
+// handled everything this client sent before, e.g. to
+// make sure that an Expect() is registered before asking
+// another process to deliver.
+// If the client is disconnected, the task is resolved
+// after it reconnects and registers its expectations again.
+// The task fails with ErrClosed if the client is closed first.
+// Example:
+//
+//	job := client.Expect("job/42")
+//	client.Sync().Await()
+//	startWorker("job/42")
+func (c *Client) Sync() quest.VoidTask {
+	task := quest.NewVoidTask()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		task.Fail(ErrClosed)
+		return task
+	}
+	c.nextID++
+	id := strconv.Itoa(c.nextID)
+	c.pings[id] = task
+	c.send(message{Op: opPing, ID: id})
+	return task
+}
+
+// Closes the connection, and cancels the pending expectations.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	expects := c.expects
+	c.expects = map[string][]quest.Task[[]byte]{}
+	pings := c.pings
+	c.pings = map[string]quest.Task[quest.Void]{}
+	conn := c.conn
+	c.mu.Unlock()
+
+	for _, tasks := range expects {
+		for _, task := range tasks {
+			task.Fail(ErrClosed)
+		}
+	}
+	for _, task := range pings {
+		task.Fail(ErrClosed)
+	}
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// Sends the message if connected.
+// Must be called with mu held.
+func (c *Client) send(msg message) bool {
+	if c.conn == nil {
+		return false
+	}
+	if err := c.enc.Encode(msg); err != nil {
+		c.conn.Close()
+		return false
+	}
+	return true
+}
+
+// Sends the message, or keeps it for after reconnecting.
+// Must be called with mu held.
+func (c *Client) redeliver(msg message) {
+	if !c.closed && !c.send(msg) {
+		c.outbox = append(c.outbox, msg)
+	}
+}
+
+func (c *Client) forget(id string, task quest.Task[[]byte]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tasks := c.expects[id]
+	for i, t := range tasks {
+		if t == task {
+			tasks = append(tasks[:i:i], tasks[i+1:]...)
+			break
+		}
+	}
+	if len(tasks) == 0 {
+		if _, ok := c.expects[id]; ok {
+			delete(c.expects, id)
+			c.send(message{Op: opUnexpect, ID: id})
+		}
+	} else {
+		c.expects[id] = tasks
+	}
+}
+
+func (c *Client) setConn(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn = conn
+	c.enc = json.NewEncoder(conn)
+
+	for id := range c.expects {
+		c.send(message{Op: opExpect, ID: id})
+	}
+	// Pings sent on the lost connection may never
+	// have been answered.
+	for id := range c.pings {
+		c.send(message{Op: opPing, ID: id})
+	}
+	outbox := c.outbox
+	c.outbox = nil
+	for i, msg := range outbox {
+		if !c.send(msg) {
+			c.outbox = append(c.outbox, outbox[i:]...)
+			break
+		}
+	}
+
+	go c.read(conn)
+}
+
+func (c *Client) read(conn net.Conn) {
+	dec := json.NewDecoder(conn)
+	for {
+		var msg message
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+		if msg.Op == opPong {
+			c.mu.Lock()
+			task := c.pings[msg.ID]
+			delete(c.pings, msg.ID)
+			c.mu.Unlock()
+			if task != nil {
+				task.Resolve(quest.None)
+			}
+			continue
+		}
+		if msg.Op != opDeliver {
+			continue
+		}
+
+		c.mu.Lock()
+		tasks, ok := c.expects[msg.ID]
+		delete(c.expects, msg.ID)
+		if !ok {
+			// The expectation was withdrawn while the payload
+			// was on its way: give it back to the broker, which
+			// handled the opUnexpect first, so that it goes to
+			// another client instead of being lost.
+			c.redeliver(msg)
+		}
+		c.mu.Unlock()
+
+		for _, task := range tasks {
+			task.Resolve(msg.Payload)
+		}
+	}
+
+	conn.Close()
+	c.reconnect(conn)
+}
+
+func (c *Client) reconnect(old net.Conn) {
+	c.mu.Lock()
+	if c.conn == old {
+		c.conn = nil
+	}
+	c.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		c.mu.Lock()
+		closed := c.closed
+		c.mu.Unlock()
+		if closed {
+			return
+		}
+
+		conn, err := net.Dial(c.network, c.address)
+		if err == nil {
+			c.setConn(conn)
+			return
+		}
+		time.Sleep(c.backoff.Next(attempt))
+	}
+}
@@ -0,0 +1,5 @@
+// This package extends quest tasks across processes.
+// A Broker routes payloads between clients: one process can
+// Expect() an id and await the payload as a task, while another
+// process Deliver()s the payload for that id.
+package remote
@@ -0,0 +1,93 @@
+package remote_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+	"github.com/nvlled/quest/remote"
+)
+
+func TestExpectDeliver(t *testing.T) {
+	broker, err := remote.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	a, err := remote.Dial("tcp", broker.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := remote.Dial("tcp", broker.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	task := a.ExpectTimeout("job", time.Second)
+	a.Sync().Await()
+	b.Deliver("job", []byte("done"))
+	if payload, ok := task.Await(); !ok || string(payload) != "done" {
+		t.Errorf("expected payload, got %q (%v)", payload, task.Error())
+	}
+
+	// delivered before expected
+	b.Deliver("early", []byte("hello"))
+	b.Sync().Await()
+	if payload, ok := a.ExpectTimeout("early", time.Second).Await(); !ok || string(payload) != "hello" {
+		t.Errorf("expected payload, got %q", payload)
+	}
+
+	timeout := a.ExpectTimeout("never", 10*time.Millisecond)
+	if _, ok := timeout.Await(); ok || timeout.Error() != quest.ErrTimeout {
+		t.Errorf("expected timeout, got %v", timeout.Error())
+	}
+
+	// the broker keeps the payload for the next client
+	a.Sync().Await()
+	b.Deliver("never", []byte("late"))
+	b.Sync().Await()
+	if payload, ok := b.ExpectTimeout("never", time.Second).Await(); !ok || string(payload) != "late" {
+		t.Errorf("payload of a withdrawn expectation should not be lost, got %q", payload)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	broker, err := remote.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := broker.Addr().String()
+
+	client, err := remote.DialBackoff("tcp", addr, quest.ConstantBackoff(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	task := client.ExpectTimeout("job", 2*time.Second)
+
+	broker.Close()
+	broker, err = remote.Listen("tcp", addr)
+	if err != nil {
+		t.Skip("address not reusable:", err)
+	}
+	defer broker.Close()
+
+	other, err := remote.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	// resolved once the client has reconnected and expects again
+	if _, ok := client.Sync().Await(); !ok {
+		t.Fatal("sync failed")
+	}
+	other.Deliver("job", []byte("after reconnect"))
+
+	if payload, ok := task.Await(); !ok || string(payload) != "after reconnect" {
+		t.Errorf("expected payload, got %q (%v)", payload, task.Error())
+	}
+}
@@ -0,0 +1,172 @@
+package quest
+
+import "sync"
+
+// An RWLock is a reader/writer lock where locking returns
+// a task, so that waiting for the lock can be awaited,
+// timed out or cancelled instead of blocking a goroutine.
+// By default, requests are granted in FIFO order, with
+// consecutive readers sharing the lock.
+// Example:
+//
+//	lock := world.Lock()
+//	if _, ok := WithDeadline[Void](lock, deadline).Await(); !ok {
+//	  if !lock.TryCancel() { world.Unlock() } // granted meanwhile
+//	  return
+//	}
+//	defer world.Unlock()
+//	state.Apply(update)
+type RWLock struct {
+	mu             sync.Mutex
+	readers        int
+	writer         bool
+	preferWriters  bool
+	waiters        []rwWaiter
+	waitingWriters int
+}
+
+type rwWaiter struct {
+	write bool
+	task  *taskImpl[Void]
+}
+
+// Options for NewRWLock().
+type RWLockOption func(*RWLock)
+
+// Grants the lock to waiting writers before waiting
+// readers, so that writers are not starved by a steady
+// flow of readers.
+func WithWriterPreference() RWLockOption {
+	return func(l *RWLock) {
+		l.preferWriters = true
+	}
+}
+
+// Creates a new unlocked RWLock.
+func NewRWLock(opts ...RWLockOption) *RWLock {
+	l := &RWLock{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Returns a task that is resolved once the lock is held
+// for reading. Cancelling the task before then withdraws
+// the request. Release the lock with RUnlock().
+func (l *RWLock) RLock() VoidTask {
+	return l.request(false)
+}
+
+// Returns a task that is resolved once the lock is held
+// for writing. Cancelling the task before then withdraws
+// the request. Release the lock with Unlock().
+func (l *RWLock) Lock() VoidTask {
+	return l.request(true)
+}
+
+// Releases a read lock.
+func (l *RWLock) RUnlock() {
+	l.mu.Lock()
+	if l.readers == 0 {
+		l.mu.Unlock()
+		panic("quest: RUnlock of unlocked RWLock")
+	}
+	l.readers--
+	granted := l.grant()
+	l.mu.Unlock()
+
+	l.resolve(granted)
+}
+
+// Releases the write lock.
+func (l *RWLock) Unlock() {
+	l.mu.Lock()
+	if !l.writer {
+		l.mu.Unlock()
+		panic("quest: Unlock of unlocked RWLock")
+	}
+	l.writer = false
+	granted := l.grant()
+	l.mu.Unlock()
+
+	l.resolve(granted)
+}
+
+func (l *RWLock) request(write bool) VoidTask {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	task := newTask[Void]()
+	l.waiters = append(l.waiters, rwWaiter{write, task})
+	if write {
+		l.waitingWriters++
+	}
+	// Only this request can be granted here, since
+	// the others would have been granted already.
+	if granted := l.grant(); len(granted) > 0 {
+		task.resolve(None)
+		return task
+	}
+
+	task.OnCancel(func() { l.withdraw(task) })
+	return task
+}
+
+func (l *RWLock) withdraw(task *taskImpl[Void]) {
+	l.mu.Lock()
+	for i, w := range l.waiters {
+		if w.task == task {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			if w.write {
+				l.waitingWriters--
+			}
+			break
+		}
+	}
+	granted := l.grant()
+	l.mu.Unlock()
+
+	l.resolve(granted)
+}
+
+// Takes the lock for the waiters that can have it,
+// and removes them from the queue.
+// Must be called with mu held.
+func (l *RWLock) grant() []rwWaiter {
+	var granted []rwWaiter
+	for i := 0; i < len(l.waiters) && !l.writer; {
+		w := l.waiters[i]
+		switch {
+		case w.write && l.readers == 0:
+			l.writer = true
+			l.waitingWriters--
+		case w.write:
+			return granted
+		case l.preferWriters && l.waitingWriters > 0:
+			// Readers wait behind any writer.
+			i++
+			continue
+		default:
+			l.readers++
+		}
+		l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+		granted = append(granted, w)
+	}
+	return granted
+}
+
+// Resolves the granted tasks outside of the lock, releasing
+// the lock for the ones cancelled in the meantime.
+func (l *RWLock) resolve(granted []rwWaiter) {
+	for _, w := range granted {
+		if w.task.resolve(None) {
+			continue
+		}
+		if w.write {
+			l.Unlock()
+		} else {
+			l.RUnlock()
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestRWLock(t *testing.T) {
+	l := quest.NewRWLock()
+	r1 := l.RLock()
+	r2 := l.RLock()
+	if !r1.IsDone() || !r2.IsDone() {
+		t.Error("readers should share the lock")
+	}
+
+	w := l.Lock()
+	r3 := l.RLock()
+	if w.IsDone() || r3.IsDone() {
+		t.Error("writer should wait for readers, and later readers for the writer")
+	}
+
+	l.RUnlock()
+	l.RUnlock()
+	if !w.IsDone() || r3.IsDone() {
+		t.Error("writer should get the lock before the next reader")
+	}
+	l.Unlock()
+	if !r3.IsDone() {
+		t.Error("reader should get the lock after the writer")
+	}
+
+	w2 := l.Lock()
+	w2.Cancel()
+	l.RUnlock()
+	if !l.Lock().IsDone() {
+		t.Error("cancelled requests should be withdrawn")
+	}
+}
+
+func TestRWLockWriterPreference(t *testing.T) {
+	l := quest.NewRWLock(quest.WithWriterPreference())
+	l.RLock()
+
+	r := l.RLock()
+	w := l.Lock()
+	later := l.RLock()
+	if !r.IsDone() {
+		t.Error("readers should share the lock while no writer waits")
+	}
+	if w.IsDone() || later.IsDone() {
+		t.Error("readers should wait behind a waiting writer")
+	}
+
+	l.RUnlock()
+	l.RUnlock()
+	if !w.IsDone() || later.IsDone() {
+		t.Error("writer should be granted first")
+	}
+	l.Unlock()
+	if !later.IsDone() {
+		t.Error("reader should be granted after the writer")
+	}
+}
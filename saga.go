@@ -0,0 +1,75 @@
+package quest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A Saga runs a sequence of steps, where each step has
+// an undo function that compensates for it. If a step
+// fails, the completed steps are undone in reverse order.
+// Example:
+//
+//	saga := NewSaga()
+//	saga.Step(reserveStock, releaseStock)
+//	saga.Step(chargeCard, refundCard)
+//	saga.Step(shipOrder, nil)
+//	if _, ok := saga.Run().Await(); !ok { ... }
+type Saga struct {
+	steps []sagaStep
+}
+
+type sagaStep struct {
+	do   func() VoidTask
+	undo func() VoidTask
+}
+
+// Creates an empty saga.
+func NewSaga() *Saga {
+	return &Saga{}
+}
+
+// Adds a step. do starts the step and returns its task,
+// undo starts the compensation of a completed step.
+// undo can be nil if the step needs no compensation.
+func (saga *Saga) Step(do func() VoidTask, undo func() VoidTask) {
+	saga.steps = append(saga.steps, sagaStep{do, undo})
+}
+
+// Runs the steps one after the other, each step starting
+// after the previous one is resolved.
+// If a step is cancelled or fails, the undo functions of
+// the completed steps are run in reverse order, each one
+// awaited before the next. The returned task then fails
+// with the error of the step, joined with the errors of
+// the undos that failed.
+func (saga *Saga) Run() VoidTask {
+	steps := append([]sagaStep(nil), saga.steps...)
+	task := newTask[Void]()
+
+	go func() {
+		for i, step := range steps {
+			stepTask := step.do()
+			if _, ok := stepTask.Await(); ok {
+				continue
+			}
+
+			errs := []error{fmt.Errorf("step %d: %w", i, cancelErrorOf(stepTask))}
+			for j := i - 1; j >= 0; j-- {
+				if steps[j].undo == nil {
+					continue
+				}
+				undo := steps[j].undo()
+				if _, ok := undo.Await(); !ok {
+					errs = append(errs, fmt.Errorf("undo step %d: %w", j, cancelErrorOf(undo)))
+				}
+			}
+
+			task.Fail(errors.Join(errs...))
+			return
+		}
+		task.Resolve(None)
+	}()
+
+	return task
+}
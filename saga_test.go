@@ -0,0 +1,49 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestSaga(t *testing.T) {
+	log := []string{}
+	step := func(name string, fail bool) func() quest.VoidTask {
+		return func() quest.VoidTask {
+			task := quest.NewVoidTask()
+			log = append(log, name)
+			if fail {
+				task.Fail(errors.New(name))
+			} else {
+				task.Resolve(quest.None)
+			}
+			return task
+		}
+	}
+
+	saga := quest.NewSaga()
+	saga.Step(step("a", false), step("undo a", false))
+	saga.Step(step("b", false), nil)
+	saga.Step(step("c", false), step("undo c", true))
+	saga.Step(step("d", true), step("undo d", false))
+
+	task := saga.Run()
+	if _, ok := task.Await(); ok {
+		t.Error("saga should fail")
+	}
+
+	expected := []string{"a", "b", "c", "d", "undo c", "undo a"}
+	if len(log) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, log)
+	}
+	for i := range expected {
+		if log[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, log)
+		}
+	}
+
+	if msg := task.Error().Error(); msg != "step 3: d\nundo step 2: undo c" {
+		t.Errorf("unexpected error: %q", msg)
+	}
+}
@@ -0,0 +1,70 @@
+package quest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Awaits all tasks for up to the given timeout, and returns
+// the values of the ones that were resolved in time, in the
+// same order as the tasks.
+// The error joins one error per task that wasn't resolved:
+// ErrTimeout for the ones still pending, the task's own error
+// for failed ones, and ErrCancelled for cancelled ones.
+// Each error is prefixed with the task's index.
+// The error is nil if all tasks were resolved.
+// Example:
+//
+//	results, err := ScatterGather(time.Second, queryA(), queryB(), queryC())
+//	if err != nil {
+//	  log.Println("partial results:", err)
+//	}
+func ScatterGather[T any](timeout time.Duration, tasks ...Awaitable[T]) ([]T, error) {
+	type indexed struct {
+		index  int
+		result Result[T]
+	}
+
+	results := make(chan indexed, len(tasks))
+	aborts := make([]func(), len(tasks))
+	for i, task := range tasks {
+		ch, abort := awaitChan(task)
+		aborts[i] = abort
+		go func(i int) {
+			if r, ok := <-ch; ok {
+				results <- indexed{i, r}
+			}
+		}(i)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	settled := make([]*Result[T], len(tasks))
+loop:
+	for range tasks {
+		select {
+		case r := <-results:
+			settled[r.index] = &r.result
+		case <-timer.C:
+			break loop
+		}
+	}
+
+	var values []T
+	var errs []error
+	for i, r := range settled {
+		switch {
+		case r == nil:
+			aborts[i]()
+			errs = append(errs, fmt.Errorf("task %d: %w", i, ErrTimeout))
+		case r.OK:
+			values = append(values, r.Value)
+		default:
+			errs = append(errs, indexedError(i, tasks[i]))
+		}
+	}
+
+	return values, errors.Join(errs...)
+}
@@ -0,0 +1,34 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestScatterGather(t *testing.T) {
+	err := errors.New("nope")
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[int]()
+	t3 := quest.NewTask[int]()
+	t4 := quest.NewTask[int]()
+
+	t1.Resolve(1)
+	t2.Fail(err)
+	t4.Resolve(4)
+
+	values, gathered := quest.ScatterGather[int](10*time.Millisecond, t1, t2, t3, t4)
+	if len(values) != 2 || values[0] != 1 || values[1] != 4 {
+		t.Errorf("expected [1 4], got %v", values)
+	}
+	if !errors.Is(gathered, err) || !errors.Is(gathered, quest.ErrTimeout) {
+		t.Errorf("expected the failure and the timeout, got %v", gathered)
+	}
+
+	_, gathered = quest.ScatterGather[int](time.Second, t1, t4)
+	if gathered != nil {
+		t.Errorf("expected no error, got %v", gathered)
+	}
+}
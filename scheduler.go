@@ -0,0 +1,349 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// A FrameScheduler queues continuations that are
+// only run when Update() is called.
+// Intended for game loops where game state must only
+// be touched from the main thread: call Update()
+// once per frame from the main thread, and everything
+// scheduled on it will run there.
+type FrameScheduler struct {
+	mu    sync.Mutex
+	queue []frameJob
+
+	frame    int64
+	gameTime time.Duration
+	waits    []frameWait
+
+	budget  time.Duration
+	offload *Executor
+
+	deterministic bool
+	watches       []frameWatch
+}
+
+type frameJob struct {
+	fn         func()
+	threadSafe bool
+}
+
+// A Then() callback on a deterministic scheduler.
+// A nil task is always ready.
+type frameWatch struct {
+	task AnyTask
+	fn   func()
+}
+
+type frameWait struct {
+	frame    int64
+	gameTime time.Duration
+	task     *taskImpl[Void]
+}
+
+// Options for NewFrameScheduler().
+type SchedulerOption func(*FrameScheduler)
+
+// Makes the scheduler run everything on the goroutine
+// calling Update(), in a deterministic order.
+// Then() callbacks on tasks are not dispatched from
+// background goroutines, instead the tasks are checked
+// on each Update() in the order Then() was called.
+// Given the same sequence of resolutions between frames,
+// callbacks and coroutines always run in the same order,
+// which makes replays and lockstep simulations reproducible.
+// Example:
+//
+//	sim := NewFrameScheduler(WithDeterministic())
+func WithDeterministic() SchedulerOption {
+	return func(s *FrameScheduler) {
+		s.deterministic = true
+	}
+}
+
+// Creates a new frame scheduler.
+func NewFrameScheduler(opts ...SchedulerOption) *FrameScheduler {
+	s := &FrameScheduler{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Same as NewFrameScheduler(WithDeterministic()).
+func NewDeterministicScheduler() *FrameScheduler {
+	return NewFrameScheduler(WithDeterministic())
+}
+
+// Queues fn to be run on the next Update().
+// Safe to call from any goroutine.
+func (s *FrameScheduler) Post(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, frameJob{fn: fn})
+}
+
+// Same as Post(), but fn is safe to run on any goroutine,
+// so it may be offloaded to the executor set with
+// SetOffload() when Update() runs out of budget.
+// Offloaded functions run concurrently with the main
+// thread and with each other, and can use RunOnMain()
+// or Post() to get back to the main thread.
+// Example:
+//
+//	scheduler.SetBudget(4 * time.Millisecond)
+//	scheduler.SetOffload(NewExecutor(0, 100))
+//	scheduler.PostThreadSafe(func() {
+//	  path := findPath(from, to)
+//	  scheduler.Post(func() { npc.Follow(path) })
+//	})
+func (s *FrameScheduler) PostThreadSafe(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, frameJob{fn: fn, threadSafe: true})
+}
+
+// Runs all continuations queued so far.
+// Also runs the functions queued by RunOnMain().
+// Continuations queued while Update() is running
+// are deferred until the next Update().
+// Must be called from the main thread.
+// Equivalent to UpdateDelta(0), game time does not advance.
+func (s *FrameScheduler) Update() {
+	s.UpdateDelta(0)
+}
+
+// Same as Update(), but also advances the game time by dt.
+// Pass the scaled frame time to slow down, or zero
+// to pause, tasks created by WaitGameTime().
+func (s *FrameScheduler) UpdateDelta(dt time.Duration) {
+	s.mu.Lock()
+	s.frame++
+	s.gameTime += dt
+	var due []*taskImpl[Void]
+	waits := s.waits[:0]
+	for _, w := range s.waits {
+		if w.task.IsDone() {
+			continue
+		}
+		if s.frame >= w.frame && s.gameTime >= w.gameTime {
+			due = append(due, w.task)
+			continue
+		}
+		waits = append(waits, w)
+	}
+	s.waits = waits
+	queue := s.queue
+	s.queue = nil
+	watches := s.watches[:0]
+	for _, w := range s.watches {
+		if w.task == nil || w.task.IsDone() {
+			queue = append(queue, frameJob{fn: w.fn})
+		} else {
+			watches = append(watches, w)
+		}
+	}
+	s.watches = watches
+	budget := s.budget
+	offload := s.offload
+	s.mu.Unlock()
+
+	// Resolved outside the lock, the callbacks
+	// may post or wait on the scheduler.
+	for _, task := range due {
+		task.Resolve(None)
+	}
+
+	start := time.Now()
+
+	if s != mainQueue {
+		Flush()
+	}
+
+	for i, job := range queue {
+		if budget > 0 && i > 0 && time.Since(start) >= budget {
+			s.carryOver(queue[i:], offload)
+			return
+		}
+		job.fn()
+	}
+}
+
+// Puts the jobs back in front of the queue, except the
+// thread-safe ones, which are given to the executor.
+func (s *FrameScheduler) carryOver(jobs []frameJob, offload *Executor) {
+	if offload != nil {
+		mainOnly := jobs[:0:0]
+		for _, job := range jobs {
+			if job.threadSafe {
+				s.offloadJob(offload, job.fn)
+			} else {
+				mainOnly = append(mainOnly, job)
+			}
+		}
+		jobs = mainOnly
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(jobs[:len(jobs):len(jobs)], s.queue...)
+}
+
+// Runs fn on the executor. If the executor doesn't
+// run it, e.g. because its queue is full, fn is
+// queued again for the next Update().
+func (s *FrameScheduler) offloadJob(e *Executor, fn func()) {
+	task := newTask[Void]()
+	ran := false
+	task.Defer(func() {
+		if !ran {
+			s.PostThreadSafe(fn)
+		}
+	})
+	e.submit(task, func() {
+		ran = true
+		fn()
+		task.Resolve(None)
+	})
+}
+
+// Limits how long Update() spends running continuations.
+// Continuations that didn't fit in the budget are
+// carried over to the next Update(), ahead of newly
+// queued ones. At least one continuation is run per
+// Update() regardless of the budget.
+// A budget of zero (the default) means no limit.
+func (s *FrameScheduler) SetBudget(budget time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budget = budget
+}
+
+// Sets the executor that continuations queued with
+// PostThreadSafe() are offloaded to when Update() runs
+// out of budget, instead of being carried over to the
+// next Update(), so that heavy frames degrade gracefully
+// instead of stuttering. nil (the default) disables it.
+func (s *FrameScheduler) SetOffload(e *Executor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offload = e
+}
+
+// Returns the number of continuations waiting
+// for the next Update().
+// On a deterministic scheduler, this includes
+// the Then() callbacks whose tasks are not yet done.
+func (s *FrameScheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue) + len(s.watches)
+}
+
+// Returns the number of Update() calls so far.
+func (s *FrameScheduler) Frame() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.frame
+}
+
+// Returns the total game time accumulated by UpdateDelta().
+func (s *FrameScheduler) GameTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gameTime
+}
+
+// Returns a task that is resolved after n more Update() calls.
+func (s *FrameScheduler) WaitFrames(n int) VoidTask {
+	return s.addWait(int64(n), 0)
+}
+
+// Returns a task that is resolved once the game time
+// has advanced by d. Unlike time.Sleep(), this respects
+// pauses and slow motion since game time only advances
+// with UpdateDelta().
+func (s *FrameScheduler) WaitGameTime(d time.Duration) VoidTask {
+	return s.addWait(0, d)
+}
+
+func (s *FrameScheduler) addWait(frames int64, d time.Duration) VoidTask {
+	s.mu.Lock()
+	w := frameWait{
+		frame:    s.frame + frames,
+		gameTime: s.gameTime + d,
+		task:     newTask[Void](),
+	}
+	due := s.frame >= w.frame && s.gameTime >= w.gameTime
+	if !due {
+		s.waits = append(s.waits, w)
+	}
+	s.mu.Unlock()
+
+	if due {
+		w.task.Resolve(None)
+	}
+	return w.task
+}
+
+// Waits for the task in the background, then
+// runs fn with the result on the scheduler's Update().
+// Example:
+//
+//	Then(scheduler, loadTexture(path), func(tex *Texture, ok bool) {
+//	  if ok { sprite.SetTexture(tex) }
+//	})
+//
+// On a deterministic scheduler, tasks that implement AnyTask
+// are polled on Update() instead of awaited in the background.
+// Other awaitables are awaited on the next Update(), in order,
+// blocking it until they are done.
+func Then[T any](s *FrameScheduler, task Awaitable[T], fn func(value T, ok bool)) {
+	if s.deterministic {
+		t, _ := task.(AnyTask)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.watches = append(s.watches, frameWatch{t, func() {
+			fn(task.Await())
+		}})
+		return
+	}
+	go func() {
+		value, ok := task.Await()
+		s.Post(func() { fn(value, ok) })
+	}()
+}
+
+// Queue of functions scheduled with RunOnMain().
+var mainQueue = NewFrameScheduler()
+
+// Schedules fn to be run on the main thread, and returns
+// a task that is resolved with what fn returns.
+// fn is run on the next Flush(), or on the next
+// Update() of any FrameScheduler.
+// Do not Await() the task from the main thread itself
+// before flushing, it will block forever.
+// Example:
+//
+//	go func() {
+//	  img := decodeImage(data)
+//	  tex, _ := RunOnMain(func() *Texture { return uploadTexture(img) }).Await()
+//	}()
+func RunOnMain[T any](fn func() T) Task[T] {
+	task := newTask[T]()
+	mainQueue.Post(func() {
+		task.Resolve(fn())
+	})
+	return task
+}
+
+// Runs the functions queued by RunOnMain().
+// Must be called from the main thread.
+// Not needed when a FrameScheduler is already updated
+// every frame.
+func Flush() {
+	mainQueue.Update()
+}
@@ -0,0 +1,320 @@
+package quest_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestFrameSchedulerThen(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	task := quest.NewTask[int]()
+	result := 0
+
+	quest.Then[int](scheduler, task, func(value int, ok bool) {
+		result = value
+	})
+
+	task.Resolve(10)
+	for scheduler.Pending() == 0 {
+		randomSleep()
+	}
+	if result != 0 {
+		t.Error("continuation should not run before Update()")
+	}
+
+	scheduler.Update()
+	if result != 10 {
+		t.Errorf("expected 10, got %v", result)
+	}
+}
+
+func TestFrameSchedulerPostDuringUpdate(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	frames := 0
+
+	scheduler.Post(func() {
+		frames++
+		scheduler.Post(func() { frames++ })
+	})
+
+	scheduler.Update()
+	if frames != 1 {
+		t.Errorf("expected 1, got %v", frames)
+	}
+	scheduler.Update()
+	if frames != 2 {
+		t.Errorf("expected 2, got %v", frames)
+	}
+}
+
+func TestCoroutine(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	signal := quest.NewTask[int]()
+	steps := []int{}
+
+	co := scheduler.Coroutine(func(co *quest.Co) {
+		steps = append(steps, 1)
+		co.Yield()
+		steps = append(steps, 2)
+		if co.Await(signal) {
+			n, _ := signal.Await()
+			steps = append(steps, n)
+		}
+	})
+
+	scheduler.Update()
+	if len(steps) != 1 {
+		t.Errorf("expected 1 step, got %v", steps)
+	}
+	scheduler.Update()
+	scheduler.Update()
+	if len(steps) != 2 {
+		t.Errorf("expected 2 steps, got %v", steps)
+	}
+
+	signal.Resolve(3)
+	scheduler.Update()
+	if len(steps) != 3 || steps[2] != 3 {
+		t.Errorf("expected 3 steps, got %v", steps)
+	}
+	if !co.IsDone() || co.IsCancelled() {
+		t.Error("coroutine should be resolved")
+	}
+}
+
+func TestCoroutineCancel(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	frames := 0
+
+	co := scheduler.Coroutine(func(co *quest.Co) {
+		for {
+			frames++
+			co.Yield()
+		}
+	})
+
+	scheduler.Update()
+	scheduler.Update()
+	co.Cancel()
+	scheduler.Update()
+	scheduler.Update()
+
+	if frames != 2 {
+		t.Errorf("expected 2 frames, got %v", frames)
+	}
+	if scheduler.Pending() != 0 {
+		t.Error("cancelled coroutine should not be rescheduled")
+	}
+
+	frames = 0
+	stubborn := scheduler.Coroutine(func(co *quest.Co) {
+		for {
+			func() {
+				defer func() { recover() }()
+				frames++
+				co.Yield()
+			}()
+		}
+	})
+	scheduler.Update()
+	stubborn.Cancel()
+	scheduler.Update()
+	scheduler.Update()
+	if frames != 1 || scheduler.Pending() != 0 {
+		t.Errorf("recover() should not keep a cancelled coroutine going, ran %v frames", frames)
+	}
+}
+
+func TestCoroutineSleep(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	co := scheduler.Coroutine(func(co *quest.Co) {
+		co.Sleep(time.Second)
+	})
+
+	scheduler.UpdateDelta(time.Second / 2)
+	scheduler.Update()
+	scheduler.UpdateDelta(time.Second / 2)
+	if co.IsDone() {
+		t.Error("sleep should last for a second of game time")
+	}
+	scheduler.UpdateDelta(time.Second / 2)
+	if !co.IsDone() {
+		t.Error("sleep should be over")
+	}
+}
+
+func TestWaitFrames(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+
+	if !scheduler.WaitFrames(0).IsDone() {
+		t.Error("waiting zero frames should resolve immediately")
+	}
+
+	task := scheduler.WaitFrames(3)
+	scheduler.Update()
+	scheduler.Update()
+	if task.IsDone() {
+		t.Error("should not resolve before the third frame")
+	}
+	scheduler.Update()
+	if !task.IsDone() {
+		t.Error("should resolve on the third frame")
+	}
+
+	// callbacks may use the scheduler
+	var next quest.VoidTask
+	posted := false
+	scheduler.WaitFrames(1).OnResolve(func() {
+		next = scheduler.WaitFrames(0)
+		scheduler.Post(func() { posted = true })
+	})
+	scheduler.Update()
+	scheduler.Update()
+	if next == nil || !next.IsDone() || !posted {
+		t.Error("callbacks should be able to post and wait")
+	}
+}
+
+func TestWaitGameTime(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	task := scheduler.WaitGameTime(100 * time.Millisecond)
+
+	scheduler.UpdateDelta(60 * time.Millisecond)
+	// paused
+	scheduler.UpdateDelta(0)
+	scheduler.Update()
+	if task.IsDone() {
+		t.Error("should not resolve while paused")
+	}
+
+	scheduler.UpdateDelta(40 * time.Millisecond)
+	if !task.IsDone() {
+		t.Error("should resolve after 100ms of game time")
+	}
+	if scheduler.GameTime() != 100*time.Millisecond || scheduler.Frame() != 4 {
+		t.Error("wrong game time or frame count")
+	}
+}
+
+func TestRunOnMain(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	onMain := false
+
+	task := quest.Start(func() int {
+		n, _ := quest.RunOnMain(func() int {
+			onMain = true
+			return 42
+		}).Await()
+		return n
+	})
+
+	for !task.IsDone() {
+		scheduler.Update()
+		randomSleep()
+	}
+
+	if n, _ := task.Await(); n != 42 || !onMain {
+		t.Errorf("expected 42, got %v", n)
+	}
+}
+
+func TestFrameSchedulerBudget(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	scheduler.SetBudget(5 * time.Millisecond)
+	order := []int{}
+
+	for i := 0; i < 4; i++ {
+		i := i
+		scheduler.Post(func() {
+			order = append(order, i)
+			time.Sleep(3 * time.Millisecond)
+		})
+	}
+
+	scheduler.Update()
+	if len(order) == 0 || len(order) == 4 {
+		t.Errorf("expected some continuations to be deferred, got %v", order)
+	}
+
+	scheduler.Post(func() { order = append(order, 4) })
+	scheduler.Update()
+	scheduler.Update()
+	for i, n := range order {
+		if i != n {
+			t.Errorf("continuations ran out of order: %v", order)
+			break
+		}
+	}
+}
+
+func TestFrameSchedulerOffload(t *testing.T) {
+	scheduler := quest.NewFrameScheduler()
+	scheduler.SetBudget(2 * time.Millisecond)
+	executor := quest.NewExecutor(2, 10)
+	scheduler.SetOffload(executor)
+
+	var offloaded, mainOnly atomic.Int32
+	scheduler.Post(func() { time.Sleep(3 * time.Millisecond) })
+	for i := 0; i < 3; i++ {
+		scheduler.PostThreadSafe(func() { offloaded.Add(1) })
+		scheduler.Post(func() { mainOnly.Add(1) })
+	}
+
+	scheduler.Update()
+	executor.Idle().Await()
+	if offloaded.Load() != 3 || mainOnly.Load() != 0 {
+		t.Errorf("expected thread-safe continuations to be offloaded, got %v, %v", offloaded.Load(), mainOnly.Load())
+	}
+	if scheduler.Pending() != 3 {
+		t.Errorf("expected main thread continuations to be carried over, got %v", scheduler.Pending())
+	}
+	scheduler.Update()
+	if mainOnly.Load() != 3 {
+		t.Errorf("expected carried over continuations to run, got %v", mainOnly.Load())
+	}
+}
+
+func TestDeterministicScheduler(t *testing.T) {
+	scheduler := quest.NewFrameScheduler(quest.WithDeterministic())
+	tasks := []quest.Task[int]{
+		quest.NewTask[int](),
+		quest.NewTask[int](),
+		quest.NewTask[int](),
+	}
+	order := []int{}
+
+	for _, task := range tasks {
+		quest.Then[int](scheduler, task, func(value int, ok bool) {
+			order = append(order, value)
+		})
+	}
+
+	tasks[2].Resolve(2)
+	tasks[1].Resolve(1)
+	tasks[0].Resolve(0)
+	scheduler.Update()
+
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("callbacks ran out of order: %v", order)
+	}
+
+	order = order[:0]
+	quest.Then[int](scheduler, quest.AwaitableFn[int](func() (int, bool) {
+		return 3, true
+	}), func(value int, ok bool) {
+		order = append(order, value)
+	})
+	last := quest.NewTask[int]()
+	last.Resolve(4)
+	quest.Then[int](scheduler, last, func(value int, ok bool) {
+		order = append(order, value)
+	})
+	scheduler.Update()
+
+	if len(order) != 2 || order[0] != 3 || order[1] != 4 {
+		t.Errorf("callbacks ran out of order: %v", order)
+	}
+}
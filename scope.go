@@ -0,0 +1,206 @@
+package quest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// A Scope groups the tasks started with Spawn(), so that
+// they can be waited for and cancelled together, and
+// none of them outlives the scope: Wait() only returns
+// once all their functions have returned.
+// Example:
+//
+//	scope := NewScope(ctx, WithFailFast())
+//	user := Spawn(scope, func(ctx context.Context) (User, error) { return fetchUser(ctx, id) })
+//	posts := Spawn(scope, func(ctx context.Context) ([]Post, error) { return fetchPosts(ctx, id) })
+//	if err := scope.Wait(); err != nil {
+//	  return err
+//	}
+type Scope struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	failFast bool
+
+	taskOpts   []TaskOption
+	namePrefix string
+	executor   *Executor
+
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	children []interface{ Cancel() }
+	errs     []error
+}
+
+// Options for NewScope().
+type ScopeOption func(*Scope)
+
+// Makes the first function that returns an error cancel
+// the other tasks of the scope and their context, and
+// Wait() return that error, like errgroup.WithContext().
+func WithFailFast() ScopeOption {
+	return func(s *Scope) {
+		s.failFast = true
+	}
+}
+
+// Sets default options for the tasks spawned in the
+// scope, e.g. WithTaskDeadline() or WithPool(), so that
+// a subsystem configures them once. The options given
+// to Spawn() are applied after these.
+// Example:
+//
+//	scope := NewScope(ctx,
+//	  WithNamePrefix("billing/"),
+//	  WithScopeDefaults(WithPool(pool), WithTaskDeadline(time.Now().Add(time.Minute))),
+//	)
+//	invoice := Spawn(scope, renderInvoice, WithName("invoice")) // named "billing/invoice"
+func WithScopeDefaults(opts ...TaskOption) ScopeOption {
+	return func(s *Scope) {
+		s.taskOpts = append(s.taskOpts, opts...)
+	}
+}
+
+// Prefixes the names of the tasks spawned in the scope.
+func WithNamePrefix(prefix string) ScopeOption {
+	return func(s *Scope) {
+		s.namePrefix += prefix
+	}
+}
+
+// Runs the functions of the tasks spawned in the scope
+// on the executor, instead of on new goroutines.
+// Functions that the executor rejects fail their task,
+// and count as errors for Wait().
+func WithScopeExecutor(e *Executor) ScopeOption {
+	return func(s *Scope) {
+		s.executor = e
+	}
+}
+
+// Creates a new scope. The functions of its tasks are
+// given a context derived from ctx, which is cancelled
+// by Cancel() and once Wait() returns.
+func NewScope(ctx context.Context, opts ...ScopeOption) *Scope {
+	s := &Scope{}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Runs fn on a new goroutine, as part of the scope.
+// The task is resolved with the value returned by fn,
+// or fails with its error. The task is cancelled right
+// away if the scope is already cancelled.
+// The task is created with the defaults of the scope,
+// followed by opts.
+// Cancelling the task doesn't stop fn, use Cancel()
+// on the scope, which also cancels the context of fn.
+func Spawn[T any](s *Scope, fn func(ctx context.Context) (T, error), opts ...TaskOption) Task[T] {
+	opts = append(s.taskOpts[:len(s.taskOpts):len(s.taskOpts)], opts...)
+	if s.namePrefix != "" {
+		opts = append(opts, func(opts *taskOptions) {
+			opts.name = s.namePrefix + opts.name
+		})
+	}
+	task := newTaskWith[T](opts)
+
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		task.Cancel()
+		return task
+	}
+	s.children = append(s.children, task)
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	run := func() {
+		value, err := fn(s.ctx)
+		if err != nil {
+			task.Fail(err)
+		} else {
+			task.Resolve(value)
+		}
+		// Also catches failures from the task options,
+		// e.g. WithTaskDeadline().
+		if task.Status() == Failed {
+			s.fail(task.Error())
+		}
+	}
+
+	if s.executor == nil {
+		go func() {
+			defer s.wg.Done()
+			run()
+		}()
+		return task
+	}
+
+	// Whichever comes first of running fn, or the task
+	// being settled without it, e.g. cancelled while
+	// queued or rejected, releases the scope.
+	var claimed atomic.Bool
+	task.Defer(func() {
+		if claimed.CompareAndSwap(false, true) {
+			if task.Status() == Failed {
+				s.fail(task.Error())
+			}
+			s.wg.Done()
+		}
+	})
+	s.executor.submit(task, func() {
+		if claimed.CompareAndSwap(false, true) {
+			defer s.wg.Done()
+			run()
+		}
+	})
+	return task
+}
+
+// Cancels the tasks of the scope and the context
+// given to their functions. Tasks spawned afterwards
+// are cancelled right away.
+func (s *Scope) Cancel() {
+	s.mu.Lock()
+	s.cancel()
+	children := s.children
+	s.mu.Unlock()
+
+	for _, child := range children {
+		child.Cancel()
+	}
+}
+
+// Waits for the functions of all the tasks of the scope
+// to return, then cancels the context of the scope.
+// Returns the errors of the tasks that failed joined
+// with errors.Join(), or only the first one with
+// WithFailFast(). Tasks cancelled with the scope
+// are not counted.
+func (s *Scope) Wait() error {
+	s.wg.Wait()
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failFast && len(s.errs) > 0 {
+		return s.errs[0]
+	}
+	return errors.Join(s.errs...)
+}
+
+func (s *Scope) fail(err error) {
+	s.mu.Lock()
+	s.errs = append(s.errs, err)
+	first := len(s.errs) == 1
+	s.mu.Unlock()
+
+	if first && s.failFast {
+		s.Cancel()
+	}
+}
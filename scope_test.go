@@ -0,0 +1,95 @@
+package quest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestScope(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	scope := quest.NewScope(context.Background())
+	ok := quest.Spawn(scope, func(ctx context.Context) (int, error) { return 1, nil })
+	quest.Spawn(scope, func(ctx context.Context) (int, error) { return 0, errA })
+	quest.Spawn(scope, func(ctx context.Context) (int, error) { return 0, errB })
+
+	err := scope.Wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("expected both errors, got %v", err)
+	}
+	if v, _ := ok.Await(); v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+	if late := quest.Spawn(scope, func(ctx context.Context) (int, error) { return 2, nil }); !late.IsCancelled() {
+		t.Error("tasks spawned after Wait() should be cancelled")
+	}
+}
+
+func TestScopeFailFast(t *testing.T) {
+	errA := errors.New("a")
+	scope := quest.NewScope(context.Background(), quest.WithFailFast())
+
+	slow := quest.Spawn(scope, func(ctx context.Context) (int, error) {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Second):
+			return 1, nil
+		}
+	})
+	quest.Spawn(scope, func(ctx context.Context) (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		return 0, errA
+	})
+
+	start := time.Now()
+	if err := scope.Wait(); err != errA {
+		t.Errorf("expected the first error, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("siblings should be cancelled on the first error")
+	}
+	if !slow.IsCancelled() || slow.Status() != quest.Cancelled {
+		t.Errorf("sibling task should be cancelled, got %v", slow.Status())
+	}
+}
+
+func TestScopeDefaults(t *testing.T) {
+	executor := quest.NewExecutor(1, 1)
+	scope := quest.NewScope(context.Background(),
+		quest.WithNamePrefix("billing/"),
+		quest.WithScopeDefaults(quest.WithTaskDeadline(time.Now().Add(20*time.Millisecond))),
+		quest.WithScopeExecutor(executor),
+	)
+
+	block := make(chan struct{})
+	invoice := quest.Spawn(scope, func(ctx context.Context) (int, error) {
+		<-block
+		return 1, nil
+	}, quest.WithName("invoice"))
+	for executor.Stats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	queued := quest.Spawn(scope, func(ctx context.Context) (int, error) { return 2, nil })
+	rejected := quest.Spawn(scope, func(ctx context.Context) (int, error) { return 3, nil })
+
+	if invoice.Name() != "billing/invoice" || queued.Name() != "billing/" {
+		t.Errorf("unexpected names %q, %q", invoice.Name(), queued.Name())
+	}
+	if !errors.Is(rejected.Error(), quest.ErrQueueFull) {
+		t.Errorf("expected the executor to be used, got %v", rejected.Error())
+	}
+
+	if _, ok := invoice.Await(); ok || invoice.Error() != quest.ErrTimeout {
+		t.Errorf("expected the scope deadline, got %v", invoice.Error())
+	}
+	close(block)
+	err := scope.Wait()
+	if !errors.Is(err, quest.ErrQueueFull) || !errors.Is(err, quest.ErrTimeout) {
+		t.Errorf("expected the rejection and the timeouts, got %v", err)
+	}
+}
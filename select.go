@@ -0,0 +1,163 @@
+package quest
+
+// A source of values for Select(),
+// created with OnTask() or OnStream().
+type SelectCase struct {
+	source selectSource
+}
+
+type selectSource interface {
+	// Starts waiting for the source.
+	start()
+	// Returns the result if it is already available.
+	poll() (Selected, bool)
+	// Sends the result to fired once available.
+	forward(index int, fired chan<- Selected)
+	// Stops waiting, giving back what was taken
+	// from the source, if anything.
+	stop()
+}
+
+// The case of Select() that fired, and its result.
+type Selected struct {
+	// The position of the case in the arguments of Select().
+	Index int
+	// The value of the task or the stream, of the type
+	// of the case, e.g. int for OnStream(*Stream[int]).
+	Value any
+	// False if the task was cancelled or failed,
+	// or if the stream was closed.
+	OK bool
+	// The error of the task, or the one given to
+	// CloseWithError() of the stream, if OK is false.
+	Err error
+}
+
+// A case of Select() that fires when the task is done.
+func OnTask[T any](task Awaitable[T]) SelectCase {
+	return SelectCase{&taskCase[T]{task: task}}
+}
+
+// A case of Select() that fires with the next value
+// of the stream, or when it is closed and drained.
+// The value is only taken from the stream if the case
+// is the one that fired.
+func OnStream[T any](s *Stream[T]) SelectCase {
+	return SelectCase{&streamCase[T]{stream: s}}
+}
+
+// Waits for the first of the cases to fire, like a select
+// statement over tasks and streams of different types.
+// If several cases are ready, the first listed one fires.
+// Blocks forever without cases.
+// Example:
+//
+//	for {
+//	  sel := Select(OnStream(input), OnStream(network), OnTask(quit))
+//	  switch sel.Index {
+//	  case 0:
+//	    handleKey(sel.Value.(Key))
+//	  case 1:
+//	    handlePacket(sel.Value.(Packet))
+//	  case 2:
+//	    return
+//	  }
+//	}
+func Select(cases ...SelectCase) Selected {
+	for _, c := range cases {
+		c.source.start()
+	}
+
+	result, found := Selected{}, false
+	for i, c := range cases {
+		if result, found = c.source.poll(); found {
+			result.Index = i
+			break
+		}
+	}
+	if !found {
+		fired := make(chan Selected, len(cases))
+		for i, c := range cases {
+			c.source.forward(i, fired)
+		}
+		result = <-fired
+	}
+
+	for i, c := range cases {
+		if i != result.Index {
+			c.source.stop()
+		}
+	}
+	return result
+}
+
+// Waits for a result on a channel from awaitChan().
+type selectWait[T any] struct {
+	result <-chan Result[T]
+	abort  func()
+	// Where the error is taken from.
+	source any
+}
+
+func (w *selectWait[T]) selected(r Result[T]) Selected {
+	selected := Selected{Value: r.Value, OK: r.OK}
+	if !r.OK {
+		selected.Err = errorOf(w.source)
+	}
+	return selected
+}
+
+func (w *selectWait[T]) poll() (Selected, bool) {
+	select {
+	case r, ok := <-w.result:
+		return w.selected(r), ok
+	default:
+		return Selected{}, false
+	}
+}
+
+func (w *selectWait[T]) forward(index int, fired chan<- Selected) {
+	go func() {
+		if r, ok := <-w.result; ok {
+			selected := w.selected(r)
+			selected.Index = index
+			fired <- selected
+		}
+	}()
+}
+
+type taskCase[T any] struct {
+	selectWait[T]
+	task Awaitable[T]
+}
+
+func (c *taskCase[T]) start() {
+	c.result, c.abort = awaitChan(c.task)
+	c.source = c.task
+}
+
+func (c *taskCase[T]) stop() {
+	c.abort()
+}
+
+type streamCase[T any] struct {
+	selectWait[T]
+	stream *Stream[T]
+	next   Task[T]
+}
+
+func (c *streamCase[T]) start() {
+	c.next = c.stream.Next()
+	c.result, c.abort = c.next.AwaitAbortable()
+	c.source = c.next
+}
+
+func (c *streamCase[T]) stop() {
+	c.abort()
+	if c.next.TryCancel() {
+		return
+	}
+	if value, ok := c.next.Value(); ok {
+		c.stream.unread(value)
+	}
+}
@@ -0,0 +1,72 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestSelect(t *testing.T) {
+	numbers := quest.NewStream[int](4)
+	words := quest.NewStream[string](4)
+	quit := quest.NewVoidTask()
+
+	time.AfterFunc(5*time.Millisecond, func() { words.Send("hello") })
+	sel := quest.Select(quest.OnStream(numbers), quest.OnStream(words), quest.OnTask[quest.Void](quit))
+	if sel.Index != 1 || sel.Value != "hello" || !sel.OK {
+		t.Errorf("expected the word, got %+v", sel)
+	}
+
+	numbers.Send(1)
+	words.Send("world")
+	sel = quest.Select(quest.OnStream(words), quest.OnStream(numbers))
+	if sel.Index != 0 || sel.Value != "world" {
+		t.Errorf("expected the first ready case to fire, got %+v", sel)
+	}
+	if n, _ := numbers.Next().Await(); n != 1 {
+		t.Errorf("values of the other cases should stay in their stream, got %v", n)
+	}
+
+	err := errors.New("gone")
+	words.CloseWithError(err)
+	sel = quest.Select(quest.OnStream(words), quest.OnStream(numbers))
+	if sel.Index != 0 || sel.OK || sel.Err != err {
+		t.Errorf("expected the closed stream, got %+v", sel)
+	}
+
+	quit.Resolve(quest.None)
+	sel = quest.Select(quest.OnStream(numbers), quest.OnTask[quest.Void](quit))
+	if sel.Index != 1 || !sel.OK {
+		t.Errorf("expected the task, got %+v", sel)
+	}
+}
+
+func TestSelectKeepsValues(t *testing.T) {
+	streams := []*quest.Stream[int]{quest.NewStream[int](0), quest.NewStream[int](0)}
+	for _, s := range streams {
+		go func(s *quest.Stream[int]) {
+			for i := 0; i < 100; i++ {
+				s.Send(i).Await()
+			}
+		}(s)
+	}
+
+	var received [2][]int
+	for n := 0; n < 200; n++ {
+		sel := quest.Select(quest.OnStream(streams[0]), quest.OnStream(streams[1]))
+		received[sel.Index] = append(received[sel.Index], sel.Value.(int))
+	}
+
+	for _, values := range received {
+		if len(values) != 100 {
+			t.Fatalf("expected 100 values, got %v", len(values))
+		}
+		for i, v := range values {
+			if i != v {
+				t.Fatalf("values were lost or reordered: %v", values)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package quest
+
+import (
+	"context"
+	"sync"
+)
+
+// A Semaphore guards a weighted resource, like
+// golang.org/x/sync/semaphore, but acquisitions are tasks
+// that can be awaited, cancelled or timed out.
+// Permits are granted in FIFO order: a large request at
+// the front of the queue is not overtaken by smaller ones.
+// Example:
+//
+//	memory := NewSemaphore(1 << 30)
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	if err := memory.AcquireContext(ctx, size); err == nil {
+//	  defer memory.Release(size)
+//	  load(asset)
+//	}
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	used    int64
+	waiters []semaphoreWaiter
+}
+
+type semaphoreWaiter struct {
+	weight int64
+	task   *taskImpl[Void]
+}
+
+// Creates a semaphore with the given total weight.
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Returns a task that is resolved once the weight is
+// acquired. Cancelling the task before then withdraws the
+// request. If the task is cancelled after being resolved,
+// the weight must still be released.
+func (s *Semaphore) Acquire(weight int64) VoidTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := newTask[Void]()
+	if len(s.waiters) == 0 && s.size-s.used >= weight {
+		s.used += weight
+		task.resolve(None)
+		return task
+	}
+
+	s.waiters = append(s.waiters, semaphoreWaiter{weight, task})
+	task.OnCancel(func() { s.withdraw(task) })
+	return task
+}
+
+// Acquires the weight without waiting.
+// Returns false if it is not available right away,
+// or if other requests are waiting.
+func (s *Semaphore) TryAcquire(weight int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.waiters) == 0 && s.size-s.used >= weight {
+		s.used += weight
+		return true
+	}
+	return false
+}
+
+// Same as Acquire(), but blocks until the weight is
+// acquired or ctx is done, in which case ctx.Err()
+// is returned and nothing is acquired.
+func (s *Semaphore) AcquireContext(ctx context.Context, weight int64) error {
+	task := s.Acquire(weight)
+	result, abort := task.AwaitAbortable()
+	defer abort()
+
+	select {
+	case <-result:
+		return nil
+	case <-ctx.Done():
+		if !task.TryCancel() {
+			// Acquired in the meantime.
+			s.Release(weight)
+		}
+		return ctx.Err()
+	}
+}
+
+// Releases the weight, and grants the waiting
+// requests that now fit, in order.
+func (s *Semaphore) Release(weight int64) {
+	s.mu.Lock()
+	s.used -= weight
+	if s.used < 0 {
+		s.mu.Unlock()
+		panic("quest: semaphore released more than acquired")
+	}
+	granted := s.grant()
+	s.mu.Unlock()
+
+	s.resolve(granted)
+}
+
+// Removes a cancelled request from the queue, which
+// may let the requests behind it be granted.
+func (s *Semaphore) withdraw(task *taskImpl[Void]) {
+	s.mu.Lock()
+	for i, w := range s.waiters {
+		if w.task == task {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			break
+		}
+	}
+	granted := s.grant()
+	s.mu.Unlock()
+
+	s.resolve(granted)
+}
+
+// Takes the weight of the waiters at the front of the
+// queue that fit. Must be called with mu held.
+func (s *Semaphore) grant() []semaphoreWaiter {
+	var granted []semaphoreWaiter
+	for len(s.waiters) > 0 && s.size-s.used >= s.waiters[0].weight {
+		w := s.waiters[0]
+		s.waiters = s.waiters[1:]
+		s.used += w.weight
+		granted = append(granted, w)
+	}
+	return granted
+}
+
+// Resolves the granted tasks outside of the lock, giving
+// back the weight of the ones cancelled in the meantime.
+func (s *Semaphore) resolve(granted []semaphoreWaiter) {
+	for _, w := range granted {
+		if !w.task.resolve(None) {
+			s.Release(w.weight)
+		}
+	}
+}
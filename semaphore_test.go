@@ -0,0 +1,46 @@
+package quest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestSemaphore(t *testing.T) {
+	s := quest.NewSemaphore(10)
+	if !s.TryAcquire(6) {
+		t.Fatal("expected to acquire 6")
+	}
+
+	large := s.Acquire(8)
+	small := s.Acquire(1)
+	if large.IsDone() || small.IsDone() {
+		t.Error("requests should wait in order, without barging")
+	}
+	if s.TryAcquire(1) {
+		t.Error("TryAcquire should not overtake waiting requests")
+	}
+
+	large.Cancel()
+	if !small.IsDone() {
+		t.Error("withdrawing the front request should grant the next one")
+	}
+
+	s.Release(6)
+	s.Release(1)
+	if !s.TryAcquire(10) {
+		t.Error("expected all weight to be available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := s.AcquireContext(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	s.Release(10)
+	if err := s.AcquireContext(context.Background(), 10); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
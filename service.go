@@ -0,0 +1,279 @@
+package quest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// A long-running component of a program,
+// managed by a Manager.
+type Service interface {
+	// Starts the service, and returns a task that is
+	// settled once the service has stopped.
+	Start() VoidTask
+	// Asks the service to stop.
+	Stop()
+	// Returns a task that is resolved once the service
+	// is ready to be used by the services depending on it.
+	Ready() VoidTask
+}
+
+// A Manager starts services after the services they depend
+// on are ready, and stops them before their dependencies.
+// Example:
+//
+//	m := NewManager()
+//	m.Add("db", db)
+//	m.Add("cache", cache)
+//	m.Add("api", api, "db", "cache")
+//	if err := m.Start(); err != nil { ... }
+//	if _, ok := m.Ready().Await(); !ok {
+//	  log.Fatal(m.Ready().Error())
+//	}
+//	// on shutdown
+//	m.Stop().Await()
+type Manager struct {
+	mu       sync.Mutex
+	services []*managedService
+	byName   map[string]*managedService
+	started  bool
+	stopping bool
+	ready    *taskImpl[Void]
+	stopped  *taskImpl[Void]
+}
+
+type managedService struct {
+	name       string
+	svc        Service
+	depNames   []string
+	deps       []*managedService
+	dependents []*managedService
+	launched   bool
+	// Resolved once svc.Start() has returned,
+	// so that Stop() is never called before it.
+	startReturned *taskImpl[Void]
+	// Settled once the service has stopped,
+	// or if it will never be started.
+	run *taskImpl[Void]
+}
+
+// Creates a new manager without services.
+func NewManager() *Manager {
+	return &Manager{
+		byName:  map[string]*managedService{},
+		ready:   newTask[Void](),
+		stopped: newTask[Void](),
+	}
+}
+
+// Adds a service, started once the services it depends on
+// are ready. Must be called before Start().
+func (m *Manager) Add(name string, svc Service, dependsOn ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := &managedService{
+		name:          name,
+		svc:           svc,
+		depNames:      dependsOn,
+		startReturned: newTask[Void](),
+		run:           newTask[Void](),
+	}
+	m.services = append(m.services, s)
+	m.byName[name] = s
+}
+
+// Starts all services, each one once its dependencies
+// are ready. Returns an error without starting anything
+// if a dependency is unknown or if there is a cycle.
+// Calling Start() again has no effect.
+func (m *Manager) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.started {
+		return nil
+	}
+	order, err := m.sortServices()
+	if err != nil {
+		return err
+	}
+	m.services = order
+	m.started = true
+
+	for _, s := range m.services {
+		go m.launch(s)
+	}
+	go m.awaitReady()
+	go func() {
+		for _, s := range m.services {
+			s.run.Await()
+		}
+		m.stopped.Resolve(None)
+	}()
+
+	return nil
+}
+
+// Returns a task that is resolved once all services are
+// ready. It fails if a service stops, or can't be started,
+// before being ready.
+func (m *Manager) Ready() VoidTask {
+	return m.ready
+}
+
+// Returns a task that is resolved once all services
+// have stopped.
+func (m *Manager) Stopped() VoidTask {
+	return m.stopped
+}
+
+// Stops the services, each one after the services that
+// depend on it have stopped. Services that are still
+// waiting for their dependencies are not started.
+// Returns the same task as Stopped().
+func (m *Manager) Stop() VoidTask {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopping {
+		return m.stopped
+	}
+	m.stopping = true
+	if !m.started {
+		m.stopped.Resolve(None)
+		return m.stopped
+	}
+
+	services := m.services
+	go func() {
+		for i := len(services) - 1; i >= 0; i-- {
+			s := services[i]
+			for _, d := range s.dependents {
+				d.run.Await()
+			}
+			m.mu.Lock()
+			launched := s.launched
+			m.mu.Unlock()
+			if launched {
+				s.startReturned.Await()
+				s.svc.Stop()
+			} else {
+				s.run.Cancel()
+			}
+		}
+	}()
+
+	return m.stopped
+}
+
+func (m *Manager) launch(s *managedService) {
+	for _, dep := range s.deps {
+		if !dep.waitReady() {
+			s.run.Fail(fmt.Errorf("service %s: dependency %s is not ready", s.name, dep.name))
+			return
+		}
+	}
+
+	m.mu.Lock()
+	if m.stopping {
+		m.mu.Unlock()
+		s.run.Cancel()
+		return
+	}
+	s.launched = true
+	m.mu.Unlock()
+
+	// Called without the lock, Start() may
+	// use the manager.
+	done := s.svc.Start()
+	s.startReturned.Resolve(None)
+
+	if _, ok := done.Await(); ok {
+		s.run.Resolve(None)
+	} else {
+		s.run.Fail(fmt.Errorf("service %s: %w", s.name, cancelErrorOf(done)))
+	}
+}
+
+func (m *Manager) awaitReady() {
+	for _, s := range m.services {
+		if !s.waitReady() {
+			err := fmt.Errorf("service %s: stopped before being ready", s.name)
+			if runErr := s.run.Error(); runErr != nil {
+				err = runErr
+			}
+			m.ready.Fail(err)
+			return
+		}
+	}
+	m.ready.Resolve(None)
+}
+
+// Waits until the service is ready, returns false
+// if it stops or fails first.
+func (s *managedService) waitReady() bool {
+	ready, abortReady := awaitChan[Void](s.svc.Ready())
+	defer abortReady()
+	run, abortRun := s.run.AwaitAbortable()
+	defer abortRun()
+
+	select {
+	case r := <-ready:
+		return r.OK
+	case <-run:
+		return false
+	}
+}
+
+// Resolves the dependencies, and returns the services
+// sorted so that dependencies come first.
+// Must be called with mu held.
+func (m *Manager) sortServices() ([]*managedService, error) {
+	for _, s := range m.services {
+		s.deps, s.dependents = nil, nil
+	}
+	for _, s := range m.services {
+		for _, name := range s.depNames {
+			dep, ok := m.byName[name]
+			if !ok {
+				return nil, fmt.Errorf("service %s: unknown dependency %s", s.name, name)
+			}
+			s.deps = append(s.deps, dep)
+			dep.dependents = append(dep.dependents, s)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*managedService]int{}
+	var order []*managedService
+	var visit func(s *managedService) error
+	visit = func(s *managedService) error {
+		switch state[s] {
+		case visiting:
+			return errors.New("dependency cycle involving service " + s.name)
+		case visited:
+			return nil
+		}
+		state[s] = visiting
+		for _, dep := range s.deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[s] = visited
+		order = append(order, s)
+		return nil
+	}
+	for _, s := range m.services {
+		if err := visit(s); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,114 @@
+package quest_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+type fakeService struct {
+	name  string
+	log   *eventLog
+	ready quest.VoidTask
+	done  quest.VoidTask
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.events, ",")
+}
+
+func newFakeService(name string, log *eventLog) *fakeService {
+	return &fakeService{name, log, quest.NewVoidTask(), quest.NewVoidTask()}
+}
+
+func (s *fakeService) Start() quest.VoidTask {
+	s.log.add("start " + s.name)
+	go func() {
+		randomSleep()
+		s.ready.Resolve(quest.None)
+	}()
+	return s.done
+}
+
+func (s *fakeService) Stop() {
+	s.log.add("stop " + s.name)
+	go s.done.Resolve(quest.None)
+}
+
+func (s *fakeService) Ready() quest.VoidTask { return s.ready }
+
+func TestManager(t *testing.T) {
+	log := &eventLog{}
+	m := quest.NewManager()
+	m.Add("api", newFakeService("api", log), "db", "cache")
+	m.Add("db", newFakeService("db", log))
+	m.Add("cache", newFakeService("cache", log), "db")
+
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Ready().Await(); !ok {
+		t.Fatalf("expected the services to be ready, got %v", m.Ready().Error())
+	}
+	if log.String() != "start db,start cache,start api" {
+		t.Errorf("unexpected start order: %v", log)
+	}
+
+	m.Stop().Await()
+	if !strings.HasSuffix(log.String(), "stop api,stop cache,stop db") {
+		t.Errorf("unexpected stop order: %v", log)
+	}
+}
+
+func TestManagerErrors(t *testing.T) {
+	m := quest.NewManager()
+	m.Add("a", newFakeService("a", &eventLog{}), "b")
+	m.Add("b", newFakeService("b", &eventLog{}), "a")
+	if err := m.Start(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+
+	m = quest.NewManager()
+	m.Add("a", newFakeService("a", &eventLog{}), "missing")
+	if err := m.Start(); err == nil || !strings.Contains(err.Error(), "unknown dependency") {
+		t.Errorf("expected an unknown dependency error, got %v", err)
+	}
+}
+
+type selfStoppingService struct {
+	*fakeService
+	m *quest.Manager
+}
+
+func (s selfStoppingService) Start() quest.VoidTask {
+	done := s.fakeService.Start()
+	s.m.Stop()
+	return done
+}
+
+func TestManagerStopFromStart(t *testing.T) {
+	log := &eventLog{}
+	m := quest.NewManager()
+	m.Add("job", selfStoppingService{newFakeService("job", log), m})
+	m.Start()
+
+	m.Stopped().Await()
+	if log.String() != "start job,stop job" {
+		t.Errorf("expected the service to start then stop, got %v", log)
+	}
+}
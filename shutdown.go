@@ -0,0 +1,129 @@
+package quest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// A ShutdownGroup stops the long-running components of
+// a program together. Each component registers the task
+// that is settled when it has stopped, and a cleanup
+// function that asks it to stop.
+// Example:
+//
+//	var group ShutdownGroup
+//	group.Register("http", serverDone, func() { server.Close() })
+//	group.Register("worker", workerDone, nil) // watches Stopping()
+//	// on SIGTERM
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := group.Shutdown(ctx); err != nil {
+//	  log.Println(err) // component worker: did not stop in time
+//	}
+type ShutdownGroup struct {
+	mu         sync.Mutex
+	components []shutdownComponent
+	stopping   Task[Void]
+	shutdown   bool
+}
+
+type shutdownComponent struct {
+	name    string
+	done    VoidTask
+	cleanup func()
+}
+
+// Registers a component. done is the task that is settled
+// once the component has stopped, and cleanup, which can be
+// nil, asks the component to stop.
+// Components registered after Shutdown() are cleaned up
+// right away, and are not awaited.
+func (g *ShutdownGroup) Register(name string, done VoidTask, cleanup func()) {
+	g.mu.Lock()
+	shutdown := g.shutdown
+	if !shutdown {
+		g.components = append(g.components, shutdownComponent{name, done, cleanup})
+	}
+	g.mu.Unlock()
+
+	if shutdown && cleanup != nil {
+		cleanup()
+	}
+}
+
+// Returns a task that is resolved when Shutdown()
+// starts, for components that stop on their own.
+func (g *ShutdownGroup) Stopping() VoidTask {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stoppingTask()
+}
+
+// Must be called with mu held.
+func (g *ShutdownGroup) stoppingTask() Task[Void] {
+	if g.stopping == nil {
+		g.stopping = NewVoidTask()
+	}
+	return g.stopping
+}
+
+// Resolves Stopping(), calls the cleanup functions in
+// the reverse order of registration, then waits for all
+// components to stop, until ctx is done.
+// Returns an error for each component that failed or
+// didn't stop in time, joined with ctx.Err() in the
+// latter case. Calling Shutdown() again has no effect.
+func (g *ShutdownGroup) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	if g.shutdown {
+		g.mu.Unlock()
+		return nil
+	}
+	g.shutdown = true
+	components := g.components
+	stopping := g.stoppingTask()
+	g.mu.Unlock()
+
+	stopping.Resolve(None)
+	for i := len(components) - 1; i >= 0; i-- {
+		if cleanup := components[i].cleanup; cleanup != nil {
+			cleanup()
+		}
+	}
+
+	var errs []error
+	timedOut := false
+	for _, c := range components {
+		result, abort := awaitChan[Void](c.done)
+		var r Result[Void]
+		stopped := true
+		select {
+		case r = <-result:
+		case <-ctx.Done():
+			// Components that stopped in the meantime
+			// are not reported.
+			select {
+			case r = <-result:
+			default:
+				stopped = false
+			}
+		}
+		abort()
+
+		switch {
+		case !stopped:
+			timedOut = true
+			errs = append(errs, fmt.Errorf("component %s: did not stop in time", c.name))
+		case !r.OK:
+			if err := errorOf(c.done); err != nil {
+				errs = append(errs, fmt.Errorf("component %s: %w", c.name, err))
+			}
+		}
+	}
+	if timedOut {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package quest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestShutdownGroup(t *testing.T) {
+	var group quest.ShutdownGroup
+	var order []string
+
+	server := quest.NewVoidTask()
+	group.Register("server", server, func() {
+		order = append(order, "server")
+		server.Resolve(quest.None)
+	})
+
+	worker := quest.NewVoidTask()
+	stopping := group.Stopping()
+	go func() {
+		stopping.Await()
+		worker.Resolve(quest.None)
+	}()
+	group.Register("worker", worker, nil)
+
+	hung := quest.NewVoidTask()
+	group.Register("hung", hung, func() { order = append(order, "hung") })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := group.Shutdown(ctx)
+
+	if strings.Join(order, ",") != "hung,server" {
+		t.Errorf("cleanups should run in reverse order, got %v", order)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "component hung") {
+		t.Errorf("expected the hung component to be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "server") || strings.Contains(err.Error(), "worker") {
+		t.Errorf("stopped components should not be reported, got %v", err)
+	}
+}
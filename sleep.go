@@ -0,0 +1,119 @@
+package quest
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Returns a task that is resolved after d.
+// All sleeps share a single timer, so thousands of them
+// can be pending at once, e.g. one delay per entity.
+// Cancelling the task removes it from the timer.
+// Example:
+//
+//	cooldown := Sleep(2 * time.Second)
+//	// ...
+//	if cooldown.IsDone() { attack() }
+func Sleep(d time.Duration) VoidTask {
+	task := newTask[Void]()
+	if d <= 0 {
+		task.resolve(None)
+		return task
+	}
+
+	entry := &sleepEntry{at: time.Now().Add(d), task: task}
+	sleepTimers.add(entry)
+	task.OnCancel(func() {
+		sleepTimers.remove(entry)
+	})
+
+	return task
+}
+
+var sleepTimers sleepHeap
+
+type sleepEntry struct {
+	at    time.Time
+	task  *taskImpl[Void]
+	index int
+}
+
+// A min-heap of the pending sleeps, ordered by due time,
+// served by one timer armed for the earliest entry.
+type sleepHeap struct {
+	mu      sync.Mutex
+	entries []*sleepEntry
+	timer   *time.Timer
+}
+
+func (h *sleepHeap) add(entry *sleepEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	heap.Push(h, entry)
+	if entry.index == 0 {
+		h.arm(time.Until(entry.at))
+	}
+}
+
+func (h *sleepHeap) remove(entry *sleepEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry.index >= 0 {
+		heap.Remove(h, entry.index)
+	}
+}
+
+// Must be called with mu held.
+func (h *sleepHeap) arm(d time.Duration) {
+	if h.timer == nil {
+		h.timer = time.AfterFunc(d, h.fire)
+		return
+	}
+	h.timer.Reset(d)
+}
+
+func (h *sleepHeap) fire() {
+	h.mu.Lock()
+	now := time.Now()
+	var due []*sleepEntry
+	for len(h.entries) > 0 && !h.entries[0].at.After(now) {
+		due = append(due, heap.Pop(h).(*sleepEntry))
+	}
+	if len(h.entries) > 0 {
+		h.arm(h.entries[0].at.Sub(now))
+	}
+	h.mu.Unlock()
+
+	for _, entry := range due {
+		entry.task.Resolve(None)
+	}
+}
+
+// heap.Interface, must be called with mu held.
+
+func (h *sleepHeap) Len() int           { return len(h.entries) }
+func (h *sleepHeap) Less(i, j int) bool { return h.entries[i].at.Before(h.entries[j].at) }
+
+func (h *sleepHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *sleepHeap) Push(x any) {
+	entry := x.(*sleepEntry)
+	entry.index = len(h.entries)
+	h.entries = append(h.entries, entry)
+}
+
+func (h *sleepHeap) Pop() any {
+	n := len(h.entries)
+	entry := h.entries[n-1]
+	h.entries[n-1] = nil
+	h.entries = h.entries[:n-1]
+	entry.index = -1
+	return entry
+}
@@ -0,0 +1,32 @@
+package quest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestSleep(t *testing.T) {
+	start := time.Now()
+	long := quest.Sleep(time.Hour)
+	tasks := make([]quest.VoidTask, 1000)
+	for i := range tasks {
+		tasks[i] = quest.Sleep(time.Duration(i%20) * time.Millisecond)
+	}
+	cancelled := quest.Sleep(5 * time.Millisecond)
+	cancelled.Cancel()
+
+	for _, task := range tasks {
+		if _, ok := task.Await(); !ok {
+			t.Fatal("sleep should be resolved")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 19*time.Millisecond {
+		t.Errorf("sleeps resolved too early: %v", elapsed)
+	}
+	if cancelled.Status() != quest.Cancelled || long.IsDone() {
+		t.Error("cancelled and long sleeps should not be resolved")
+	}
+	long.Cancel()
+}
@@ -0,0 +1,440 @@
+package quest
+
+import (
+	"errors"
+	"sync"
+)
+
+// The error set by Fail() on tasks returned by Send()
+// when the stream is closed.
+var ErrStreamClosed = errors.New("stream closed")
+
+// A Stream is a bounded, awaitable queue of values.
+// Send() waits while the buffer is full, Next() waits
+// while it is empty. Each value is received by exactly
+// one Next() call.
+// Unlike channels, sending to a closed stream doesn't
+// panic, and both ends can give up waiting by cancelling
+// their tasks.
+type Stream[T any] struct {
+	mu       sync.Mutex
+	buffer   []T
+	capacity int
+	closed   bool
+	err      error
+
+	senders   []streamSend[T]
+	receivers []*taskImpl[T]
+	// Callbacks of the tasks resolved with mu held,
+	// run by unlock().
+	callbacks []func()
+
+	replaySize int
+	replay     []T
+	// Replaced rather than modified in place, so that
+	// the deliveries can keep a copy.
+	subscribers []*Stream[T]
+	// Values published with mu held, given to the
+	// subscribers by deliver() one at a time, in order.
+	deliveries []streamDelivery[T]
+	delivering bool
+}
+
+// Options for stream construction.
+type StreamOption func(*streamOptions)
+
+type streamOptions struct {
+	replay int
+}
+
+// Keeps the last n sent values, which are given first
+// to the streams created with Subscribe(), so that late
+// subscribers start with the current state, e.g. the
+// current HP or the current scene.
+func WithReplay(n int) StreamOption {
+	return func(opts *streamOptions) {
+		opts.replay = n
+	}
+}
+
+type streamSend[T any] struct {
+	value T
+	task  *taskImpl[Void]
+}
+
+type streamDelivery[T any] struct {
+	value       T
+	subscribers []*Stream[T]
+	// Closes the subscribers with err instead
+	// of sending the value.
+	close bool
+	err   error
+}
+
+// Creates a new stream that buffers up to capacity values.
+// With zero capacity, each Send() waits for a Next().
+// Example:
+//
+//	NewStream[int](10)
+//	NewStream[Scene](1, WithReplay(1))
+func NewStream[T any](capacity int, opts ...StreamOption) *Stream[T] {
+	var options streamOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Stream[T]{capacity: capacity, replaySize: options.replay}
+}
+
+// Returns a new stream that receives a copy of every
+// value sent from now on, preceded by the values kept
+// with WithReplay(). Subscribers don't take values away
+// from Next(), and never slow down the senders: values
+// that don't fit in the subscriber's buffer are dropped.
+// The buffer holds at least one value.
+// The subscription ends when either stream is closed.
+// Example:
+//
+//	hp := NewStream[int](1, WithReplay(1))
+//	hp.Send(100)
+//	current, _ := hp.Subscribe().Next().Await() // 100
+func (s *Stream[T]) Subscribe() *Stream[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capacity := s.capacity
+	if s.replaySize > capacity {
+		capacity = s.replaySize
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	sub := NewStream[T](capacity)
+	for _, value := range s.replay {
+		sub.Send(value)
+	}
+	if s.closed {
+		sub.Close()
+		return sub
+	}
+	s.subscribers = append(s.subscribers[:len(s.subscribers):len(s.subscribers)], sub)
+	return sub
+}
+
+// Creates a closed stream that yields the given values.
+func StreamOf[T any](values ...T) *Stream[T] {
+	s := &Stream[T]{
+		buffer:   append([]T(nil), values...),
+		capacity: len(values),
+	}
+	s.Close()
+	return s
+}
+
+// Returns a stream of the results of fn, called with
+// i from 0 to n-1, or forever if n is negative.
+// The stream is closed with the error returned by fn, if any.
+// Closing the stream stops the calls.
+// Example:
+//
+//	pages := Repeat(-1, func(i int) (Page, error) {
+//	  page, err := fetchPage(i)
+//	  if err == nil && page.Last { err = io.EOF }
+//	  return page, err
+//	})
+func Repeat[T any](n int, fn func(i int) (T, error)) *Stream[T] {
+	s := NewStream[T](0)
+	go func() {
+		for i := 0; n < 0 || i < n; i++ {
+			value, err := fn(i)
+			if err != nil {
+				s.CloseWithError(err)
+				return
+			}
+			if _, ok := s.Send(value).Await(); !ok {
+				return
+			}
+		}
+		s.Close()
+	}()
+	return s
+}
+
+// Sends a value to the stream.
+// The returned task is resolved once the value has been
+// buffered or received, and fails with ErrStreamClosed
+// if the stream is closed first.
+// Cancelling the task before it resolves withdraws the value.
+func (s *Stream[T]) Send(value T) VoidTask {
+	s.mu.Lock()
+	defer s.unlock()
+
+	task := newTask[Void]()
+	switch {
+	case s.closed:
+		task.Fail(ErrStreamClosed)
+	case len(s.buffer) == 0 && s.handOff(value):
+		s.publish(value)
+		task.resolve(None)
+	case len(s.buffer) < s.capacity:
+		s.buffer = append(s.buffer, value)
+		s.publish(value)
+		task.resolve(None)
+	default:
+		s.senders = append(s.senders, streamSend[T]{value, task})
+	}
+
+	return task
+}
+
+// Same as Send(), but drops the value instead of waiting
+// when the buffer is full. Returns false if the stream
+// is closed.
+func (s *Stream[T]) offer(value T) bool {
+	s.mu.Lock()
+	defer s.unlock()
+
+	if s.closed {
+		return false
+	}
+	if len(s.buffer) == 0 && s.handOff(value) {
+		s.publish(value)
+	} else if len(s.buffer) < s.capacity {
+		s.buffer = append(s.buffer, value)
+		s.publish(value)
+	}
+	return true
+}
+
+// Returns a task that is resolved with the next value.
+// Once the stream is closed and drained, the task is
+// cancelled, or fails with the error given to CloseWithError().
+// Cancelling the task before it resolves gives up the claim
+// on the next value.
+func (s *Stream[T]) Next() Task[T] {
+	s.mu.Lock()
+	defer s.unlock()
+
+	task := newTask[T]()
+
+	if len(s.buffer) > 0 {
+		var empty T
+		task.resolve(s.buffer[0])
+		s.buffer[0] = empty
+		s.buffer = s.buffer[1:]
+		s.fillBuffer()
+		return task
+	}
+
+	for len(s.senders) > 0 {
+		send := s.senders[0]
+		s.senders = s.senders[1:]
+		if s.resolveSend(send) {
+			s.publish(send.value)
+			task.resolve(send.value)
+			return task
+		}
+	}
+
+	if s.closed {
+		task.Fail(s.err)
+		return task
+	}
+
+	s.receivers = append(s.receivers, task)
+	return task
+}
+
+// Closes the stream. Values already buffered can still
+// be received, pending senders fail with ErrStreamClosed.
+// No effect if the stream is already closed.
+func (s *Stream[T]) Close() {
+	s.CloseWithError(nil)
+}
+
+// Same as Close(), but receivers fail with the given
+// error once the stream is drained.
+func (s *Stream[T]) CloseWithError(err error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+
+	senders, receivers := s.senders, s.receivers
+	s.senders = nil
+	s.receivers = nil
+	if len(s.subscribers) > 0 {
+		// Queued after the values still being delivered.
+		s.deliveries = append(s.deliveries, streamDelivery[T]{
+			subscribers: s.subscribers,
+			close:       true,
+			err:         err,
+		})
+		s.subscribers = nil
+	}
+	s.mu.Unlock()
+
+	// Failed without the lock, since callbacks
+	// of the tasks may use the stream.
+	for _, send := range senders {
+		send.task.Fail(ErrStreamClosed)
+	}
+	for _, receiver := range receivers {
+		receiver.Fail(err)
+	}
+	s.deliver()
+}
+
+// Returns true if Close() or CloseWithError() has been called.
+func (s *Stream[T]) IsClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Returns the error given to CloseWithError(), if any.
+func (s *Stream[T]) Error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Copies the value to the subscribers and the replay
+// buffer, once it has been buffered or received.
+// Values of senders still waiting for room are not
+// published, since they may be withdrawn.
+// The subscribers get the value after mu is unlocked.
+// Must be called with mu held.
+func (s *Stream[T]) publish(value T) {
+	if s.replaySize > 0 {
+		if len(s.replay) == s.replaySize {
+			s.replay = s.replay[1:]
+		}
+		s.replay = append(s.replay, value)
+	}
+	if len(s.subscribers) > 0 {
+		s.deliveries = append(s.deliveries, streamDelivery[T]{
+			value:       value,
+			subscribers: s.subscribers,
+		})
+	}
+}
+
+// Gives the published values to the subscribers, without
+// holding mu, since the subscribers have their own locks
+// and callbacks. Only one call delivers at a time so that
+// the values arrive in the order they were published.
+// Closed subscribers are dropped.
+func (s *Stream[T]) deliver() {
+	s.mu.Lock()
+	if s.delivering {
+		s.mu.Unlock()
+		return
+	}
+	s.delivering = true
+	for len(s.deliveries) > 0 {
+		deliveries := s.deliveries
+		s.deliveries = nil
+		s.mu.Unlock()
+
+		var closed []*Stream[T]
+		for _, d := range deliveries {
+			for _, sub := range d.subscribers {
+				if d.close {
+					sub.CloseWithError(d.err)
+				} else if !sub.offer(d.value) {
+					closed = append(closed, sub)
+				}
+			}
+		}
+
+		s.mu.Lock()
+		s.dropSubscribers(closed)
+	}
+	s.delivering = false
+	s.mu.Unlock()
+}
+
+// Must be called with mu held.
+func (s *Stream[T]) dropSubscribers(closed []*Stream[T]) {
+	if len(closed) == 0 {
+		return
+	}
+	isClosed := make(map[*Stream[T]]bool, len(closed))
+	for _, sub := range closed {
+		isClosed[sub] = true
+	}
+	var open []*Stream[T]
+	for _, sub := range s.subscribers {
+		if !isClosed[sub] {
+			open = append(open, sub)
+		}
+	}
+	s.subscribers = open
+}
+
+// Gives the value directly to a waiting receiver.
+// Receivers that have been cancelled are skipped.
+// Must be called with mu held.
+func (s *Stream[T]) handOff(value T) bool {
+	for len(s.receivers) > 0 {
+		receiver := s.receivers[0]
+		s.receivers = s.receivers[1:]
+		if callbacks, ok := receiver.resolveDeferred(value); ok {
+			s.callbacks = append(s.callbacks, callbacks...)
+			return true
+		}
+	}
+	return false
+}
+
+// Resolves the task of a waiting sender.
+// Returns false if it has been cancelled.
+// Must be called with mu held.
+func (s *Stream[T]) resolveSend(send streamSend[T]) bool {
+	callbacks, ok := send.task.resolveDeferred(None)
+	s.callbacks = append(s.callbacks, callbacks...)
+	return ok
+}
+
+// Unlocks mu, then runs the callbacks of the tasks
+// resolved meanwhile, since they may use the stream,
+// and delivers the published values.
+func (s *Stream[T]) unlock() {
+	callbacks := s.callbacks
+	deliver := len(s.deliveries) > 0
+	s.callbacks = nil
+	s.mu.Unlock()
+	runCallbacks(callbacks)
+	if deliver {
+		s.deliver()
+	}
+}
+
+// Puts back a value taken by Next(), so that it is the
+// next one received. The buffer may exceed its capacity
+// until the value is received.
+func (s *Stream[T]) unread(value T) {
+	s.mu.Lock()
+	defer s.unlock()
+
+	if len(s.buffer) == 0 && s.handOff(value) {
+		return
+	}
+	s.buffer = append([]T{value}, s.buffer...)
+}
+
+// Moves the values of waiting senders into the buffer.
+// Must be called with mu held.
+func (s *Stream[T]) fillBuffer() {
+	for len(s.buffer) < s.capacity && len(s.senders) > 0 {
+		send := s.senders[0]
+		s.senders = s.senders[1:]
+		if s.resolveSend(send) {
+			s.buffer = append(s.buffer, send.value)
+			s.publish(send.value)
+		}
+	}
+}
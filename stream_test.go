@@ -0,0 +1,149 @@
+package quest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestStream(t *testing.T) {
+	s := quest.NewStream[int](1)
+
+	t1 := s.Send(1)
+	t2 := s.Send(2)
+	if !t1.IsDone() || t2.IsDone() {
+		t.Error("send should only block when the buffer is full")
+	}
+	// the stream must not be locked while callbacks run
+	t2.OnResolve(func() { s.IsClosed() })
+
+	if n, _ := s.Next().Await(); n != 1 {
+		t.Errorf("expected 1, got %v", n)
+	}
+	if !t2.IsDone() {
+		t.Error("pending send should be buffered after a receive")
+	}
+
+	s.Close()
+	if _, ok := s.Send(3).Await(); ok {
+		t.Error("send to a closed stream should fail")
+	}
+	if n, ok := s.Next().Await(); n != 2 || !ok {
+		t.Errorf("buffered values should be received after close, got %v", n)
+	}
+	if _, ok := s.Next().Await(); ok {
+		t.Error("drained stream should cancel Next()")
+	}
+}
+
+func TestStreamCloseWithError(t *testing.T) {
+	err := errors.New("nope")
+	s := quest.NewStream[int](0)
+	next := s.Next()
+	next.OnCancel(func() { s.Send(1) })
+	s.CloseWithError(err)
+	if _, ok := next.Await(); ok || next.Error() != err {
+		t.Error("waiting receivers should fail with the close error")
+	}
+}
+
+func TestStreamReplay(t *testing.T) {
+	s := quest.NewStream[int](3, quest.WithReplay(2))
+	s.Send(1)
+	s.Send(2)
+	s.Send(3)
+
+	sub := s.Subscribe()
+	s.Send(4)
+	s.Send(5).Cancel()
+
+	if n, _ := s.Next().Await(); n != 1 {
+		t.Errorf("subscribers should not take values from the stream, got %v", n)
+	}
+	for _, expected := range []int{2, 3, 4} {
+		if n, _ := sub.Next().Await(); n != expected {
+			t.Errorf("expected %v, got %v", expected, n)
+		}
+	}
+	if next := sub.Next(); next.IsDone() {
+		t.Error("withdrawn values should not be published")
+	}
+
+	s.Close()
+	if _, ok := sub.Next().Await(); ok {
+		t.Error("subscriber should be closed with the stream")
+	}
+}
+
+func TestStreamSlowSubscriber(t *testing.T) {
+	s := quest.NewStream[int](1)
+	sub := s.Subscribe()
+	for i := 1; i <= 3; i++ {
+		s.Send(i)
+		s.Next().Await()
+	}
+	if n, _ := sub.Next().Await(); n != 1 {
+		t.Errorf("expected 1, got %v", n)
+	}
+	if sub.Next().IsDone() {
+		t.Error("values that don't fit should be dropped")
+	}
+
+	// callbacks of the subscriber may send to the stream
+	var got []int
+	echo := s.Subscribe()
+	var next func(int)
+	next = func(n int) {
+		got = append(got, n)
+		if n < 3 {
+			s.Send(n + 1)
+			s.Next()
+		}
+		quest.Watch(echo.Next(), next)
+	}
+	quest.Watch(echo.Next(), next)
+	s.Send(1)
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	squares := quest.Repeat(3, func(i int) (int, error) { return i * i, nil })
+	for _, expected := range []int{0, 1, 4} {
+		if n, _ := squares.Next().Await(); n != expected {
+			t.Errorf("expected %v, got %v", expected, n)
+		}
+	}
+	if _, ok := squares.Next().Await(); ok {
+		t.Error("stream should be closed after n values")
+	}
+
+	err := errors.New("last page")
+	pages := quest.Repeat(-1, func(i int) (int, error) {
+		if i == 2 {
+			return 0, err
+		}
+		return i, nil
+	})
+	pages.Next().Await()
+	pages.Next().Await()
+	if _, ok := pages.Next().Await(); ok || pages.Error() != err {
+		t.Errorf("expected the error of fn, got %v", pages.Error())
+	}
+
+	var calls atomic.Int32
+	endless := quest.Repeat(-1, func(i int) (int, error) {
+		calls.Add(1)
+		return i, nil
+	})
+	endless.Next().Await()
+	endless.Close()
+	time.Sleep(5 * time.Millisecond)
+	if n := calls.Load(); n > 3 {
+		t.Errorf("closing the stream should stop the calls, got %v", n)
+	}
+}
@@ -2,8 +2,13 @@ package quest
 
 import (
 	"errors"
+	"fmt"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/nvlled/mud"
 )
 
 // A type representing none.
@@ -30,6 +35,76 @@ const (
 	taskCanceled taskStatus = 2
 )
 
+// Wraps the errors that combinators such as AwaitAllErr()
+// or Join2() propagate from their tasks, to tell which
+// task produced the error. Also set by Fail() on tasks
+// created WithStackTrace(). The message is the one of Err.
+// Example:
+//
+//	var taskErr *TaskError
+//	if errors.As(AwaitAllErr(uploads...), &taskErr) {
+//	  log.Printf("upload %s failed: %v", taskErr.Name, taskErr.Err)
+//	}
+type TaskError struct {
+	ID   int64
+	Name string
+	Err  error
+	// The stack of the goroutine that called Fail(),
+	// with WithStackTrace(). nil otherwise.
+	Stack []byte
+}
+
+func (e *TaskError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Returns the innermost TaskError of err, which is the
+// task where the error originated when combinators are
+// nested. ok is false if err has no TaskError.
+func LeafTaskError(err error) (leaf *TaskError, ok bool) {
+	for errors.As(err, &leaf) {
+		ok = true
+		var inner *TaskError
+		if !errors.As(leaf.Err, &inner) {
+			break
+		}
+		err = leaf.Err
+	}
+	return leaf, ok
+}
+
+// The state of a task, as returned by Status().
+type Status int
+
+const (
+	// Not yet settled.
+	Pending Status = iota
+	// Settled with Resolve().
+	Resolved
+	// Settled with Cancel().
+	Cancelled
+	// Settled with Fail().
+	Failed
+)
+
+func (status Status) String() string {
+	switch status {
+	case Pending:
+		return "pending"
+	case Resolved:
+		return "resolved"
+	case Cancelled:
+		return "cancelled"
+	case Failed:
+		return "failed"
+	}
+	return "unknown"
+}
+
 // A read-only interface of the Task.
 // Used on AwaitAll, AwaitSome, and other AwaitN
 // functions.
@@ -44,43 +119,183 @@ type Task[T any] interface {
 	// Mostly used for debugging.
 	ID() int64
 
+	// Returns the name given with WithName(),
+	// or an empty string.
+	Name() string
+
+	// Attaches a value to the task, like context values,
+	// e.g. a request or trace ID for hooks and debugging.
+	// Metadata is kept across Reset().
+	SetMeta(key, value any)
+
+	// Returns the value attached with SetMeta(),
+	// or nil if there is none.
+	Meta(key any) any
+
 	// Waits for task to finish, and returns a result.
 	// valid is false if it failed or was cancelled.
 	// Blocks the thread until it is available.
+	// Waiters blocked in Await() or AwaitNext() are
+	// released one after another in FIFO order, and each is
+	// given the result of the settlement it waited for,
+	// so no waiter can be starved by later Reset() cycles.
 	Await() (result T, valid bool)
 
+	// Same as Await(), but the result is delivered on
+	// a channel, and the wait can be stopped with abort()
+	// without cancelling the task, e.g. when the screen
+	// that displays the result is closed.
+	// The channel receives at most one result, and is
+	// closed afterwards or when aborted.
+	// Never panics, even with SetPanic(true).
+	AwaitAbortable() (result <-chan Result[T], abort func())
+
+	// Returns the result without blocking.
+	// ok is false if the task is not yet resolved,
+	// or if it was cancelled.
+	Value() (result T, ok bool)
+
+	// Waits for the next time the task is settled, and
+	// returns the result. Unlike Await(), a result that
+	// was already available before the call is ignored:
+	// if the task is done, it waits for a Reset() followed
+	// by another Resolve(), Cancel() or Fail().
+	AwaitNext() (result T, valid bool)
+
+	// Same as Await(), but calls onTick with the time
+	// spent waiting every interval until the task is done,
+	// from the waiting goroutine, e.g. to log that a long
+	// wait is still going on or to update a spinner.
+	// A non-positive interval never ticks.
+	// Example:
+	//
+	//	migration.AwaitWithTicker(10*time.Second, func(elapsed time.Duration) {
+	//	  log.Printf("still migrating after %v", elapsed)
+	//	})
+	AwaitWithTicker(interval time.Duration, onTick func(elapsed time.Duration)) (result T, valid bool)
+
 	// Resets the task, making the task available again for
 	// Resolve(), Cancel() and Error().
-	// Clears the errors if any.
-	// Sets panic to false.
+	// Clears the errors if any, unless WithStickyError() is used.
+	// Sets panic to false, unless WithStickyPanic() is used.
 	// success is false if no effect is done.
 	Reset() (success bool)
 
+	// Same as Reset(), but first waits until the goroutines
+	// that were blocked in Await() or AwaitNext() when the
+	// task was settled have returned with the result.
+	// Useful for tasks reset in a loop, to make sure every
+	// awaiter has seen the result before the next cycle.
+	ResetAfterAwaiters() (success bool)
+
+	// When enabled, Await() and AwaitNext() throw (panic)
+	// ErrCancelled instead of returning if the task
+	// is cancelled or failed.
+	SetPanic(enabled bool)
+
+	// Sets the error reported for the task when it is
+	// cancelled, instead of ErrCancelled, e.g. ErrScreenClosed.
+	// It is returned by Error() on cancelled tasks, and thrown
+	// by Await() with SetPanic(true). Kept across Reset().
+	SetCancelError(err error)
+
 	// Resolves the task result.
 	// No effect if task is already Resolve() or Cancel(),
 	// unless Reset() is called.
 	Resolve(result T)
 
+	// Same as Resolve(), but returns true only if
+	// the task was settled by this call.
+	// Useful for racing producers that need to know whether
+	// they have to clean up the value that didn't win.
+	TryResolve(result T) (success bool)
+
+	// Resolves the task with the value returned by fn.
+	// fn is only called if the task can still be resolved,
+	// so expensive computations are skipped when another
+	// producer has already settled the task.
+	// fn is called while the task is locked,
+	// it must not call any methods of the task.
+	// Returns true if the task was resolved by this call.
+	ResolveWith(fn func() T) (success bool)
+
 	// Cancels the task.
 	Cancel()
 
+	// Same as Cancel(), but returns true only if
+	// the task was settled by this call.
+	TryCancel() (success bool)
+
 	// Cancel() the task, then sets the error.
 	// The error can be retrieved with Error()
 	Fail(error)
 
-	// Returns the error set by Fail().
+	// Sets the value returned by Await() when the
+	// task is cancelled or failed, instead of the zero value.
+	// Reset() also restores the result to this value.
+	SetDefaultValue(value T)
+
+	// Registers fn to be called on every Resolve() from now
+	// on, including the ones after a Reset(), until
+	// unsubscribe() is called. See also Watch().
+	OnResolve(fn func()) (unsubscribe func())
+
+	// Registers fn to be called once when the task
+	// is cancelled or failed, e.g. to close resources
+	// tied to the unfinished work.
+	// fn is called immediately if the task is already
+	// cancelled, and is discarded if the task is resolved.
+	OnCancel(fn func())
+
+	// Registers fn to be called once when the task is
+	// settled by any means. Deferred functions are called
+	// in LIFO order, after the OnCancel() callbacks.
+	// fn is called immediately if the task is already done.
+	Defer(fn func())
+
+	// Returns the error set by Fail(), or the one
+	// set by SetCancelError() if the task is cancelled.
 	// returns nil if there is none.
 	Error() error
 
 	// Returns true if Cancel() or Fail() is called.
 	IsCancelled() (done bool)
 
+	// Returns the current state of the task.
+	Status() Status
+
+	// Returns the number of goroutines currently
+	// blocked in Await() or AwaitNext().
+	WaiterCount() int
+
+	// Returns when the task was created, last reset and
+	// settled. Only recorded for tasks created WithTiming().
+	Timestamps() Timestamps
+
+	// Returns the time from the creation, or the last
+	// Reset(), to the settlement of the task, or until now
+	// if it is still pending. Zero without WithTiming().
+	Duration() time.Duration
+
+	// Returns a stream of the states the task goes through
+	// from now on, including Pending after each Reset().
+	// Close the stream to stop receiving the changes.
+	// Changes are sent after the task is unlocked, and
+	// never slow down the task: they are dropped while
+	// the buffer of the stream is full.
+	StateChanges() *Stream[Status]
+
 	// Returns true if Resolve(), Cancel() or Fail() is called.
 	IsDone() (done bool)
 }
 
 var idGen atomic.Int64
 
+// The buffer size of the StateChanges() streams.
+// Changes that don't fit are dropped, since the task
+// never waits for slow readers.
+const stateChangesBuffer = 16
+
 // A void task represents tasks that doesn't
 // return any result.
 type VoidTask = Task[Void]
@@ -91,11 +306,90 @@ type taskImpl[T any] struct {
 	value        T
 	defaultValue T
 	status       taskStatus
+	autoReset    bool
+	overwrite    bool
+	// Lets Cancel() and Fail() replace a resolved value,
+	// for Computed().
+	cancelResolved bool
+	panics         bool
+	failed         bool
+	opts           taskOptions
+
+	onCancel []func()
+	deferred []func()
+	watchers []*func(T)
+	meta     map[any]any
+	changes  []*Stream[Status]
+	history  *resolutionRing
+
+	// Statuses not yet sent to the changes streams,
+	// see deliverChanges().
+	pendingChanges    []Status
+	deliveringChanges bool
 
-	awaitMu   sync.RWMutex
 	resolveMu sync.Mutex
 
-	err error
+	// Settled together with the task, allocated
+	// only when someone has to wait.
+	done *taskWait[T]
+	// Becomes done after the next Reset(),
+	// used by waiters that skip the current cycle.
+	nextDone *taskWait[T]
+	// The wait of the last settlement, if anyone waited.
+	settled *taskWait[T]
+
+	err       error
+	cancelErr error
+
+	waiting atomic.Int32
+	// Set when the result is awaited or read, used by
+	// the orphan and idle hooks. Cleared by Reset().
+	awaited bool
+	// Incremented on each settlement, and when the task is
+	// freed, so that idle checks can tell if the task was
+	// settled again or freed since.
+	cycle int
+
+	times Timestamps
+}
+
+// Holds the result of one settlement, so that
+// waiters get the value they were woken up for
+// even if the task is reset right after.
+// Goroutines blocked in Await() each take a turn, in
+// the order they started waiting. Only the first turn
+// is given on settlement, and each waiter passes the
+// next one on once it has its result, which gives the
+// FIFO guarantee documented on Await().
+type taskWait[T any] struct {
+	ch     chan struct{}
+	turns  []chan struct{}
+	value  T
+	ok     bool
+	panics bool
+	err    error
+
+	// The goroutines that are blocked on ch,
+	// or still reading the result.
+	readers sync.WaitGroup
+}
+
+// The times recorded for tasks created WithTiming().
+// Times of events that didn't happen are zero.
+type Timestamps struct {
+	Created time.Time
+	// The last Reset().
+	Reset time.Time
+	// Cleared by Reset().
+	Resolved  time.Time
+	Cancelled time.Time
+}
+
+// The result of a task, as a single value.
+// OK is false if the task was cancelled.
+type Result[T any] struct {
+	Value T
+	OK    bool
 }
 
 // Regular functions that returns (T, bool)
@@ -108,27 +402,123 @@ func (fn AwaitableFn[T]) Await() (T, bool) {
 
 func newTask[T any]() *taskImpl[T] {
 	t := &taskImpl[T]{}
-	t.awaitMu.Lock()
 	t.id = idGen.Add(1)
 	return t
 }
 
+func newTaskWith[T any](opts []TaskOption) *taskImpl[T] {
+	var options taskOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var t *taskImpl[T]
+	if options.pool != nil {
+		t = mud.Alloc(options.pool, newTask[T])
+		t.Reset()
+		countersOf[T](options.pool).allocs.Add(1)
+	} else {
+		t = newTask[T]()
+	}
+	t.opts = options
+	t.autoReset = options.autoReset
+	t.overwrite = false
+	t.cancelErr = options.cancelErr
+
+	if options.defaultValue != nil {
+		value, ok := convertDefault[T](options.defaultValue)
+		if !ok {
+			var empty T
+			panic(fmt.Sprintf("quest: WithDefaultValue(%T) used on a Task[%T]", options.defaultValue, empty))
+		}
+		t.defaultValue = value
+		t.value = value
+	}
+	if t.opts.timing {
+		t.times.Created = time.Now()
+	}
+	if t.opts.checkpointer != nil {
+		t.restoreCheckpoint()
+	}
+	if !options.deadline.IsZero() {
+		timer := time.AfterFunc(time.Until(options.deadline), func() {
+			t.Fail(ErrTimeout)
+		})
+		t.Defer(func() { timer.Stop() })
+	}
+	return t
+}
+
 // Creates a new task
 // Example:
 //
 //	NewTask[int]()
 //	NewTask[string]()
-//	NewTask[Event]()
-func NewTask[T any]() Task[T] {
-	return newTask[T]()
+//	NewTask[Event](WithStickyError())
+func NewTask[T any](opts ...TaskOption) Task[T] {
+	return newTaskWith[T](opts)
 }
 
 // Creates a new void task
 // Equivalent to NewTask[Void]()
 // Void tasks are resolved with None,
 // e.g. NewVoidTask().Resolve(None)
-func NewVoidTask() VoidTask {
-	return newTask[Void]()
+func NewVoidTask(opts ...TaskOption) VoidTask {
+	return newTaskWith[Void](opts)
+}
+
+// Creates a new multi-shot task.
+// Each Resolve() represents a new event: it is never
+// ignored, the task is implicitly Reset() first if needed.
+// Await() always waits for the next resolution after
+// the call, instead of returning a previous result.
+// Cancel() and Fail() likewise only settle the current cycle.
+// Example:
+//
+//	clicked := NewMultiTask[Point]()
+//	go func() {
+//	  for {
+//	    pos, _ := clicked.Await()
+//	    // handle each click
+//	  }
+//	}()
+//	clicked.Resolve(Point{1, 2})
+func NewMultiTask[T any](opts ...TaskOption) Task[T] {
+	t := newTaskWith[T](opts)
+	t.autoReset = true
+	return t
+}
+
+// Creates a new task that holds the latest known value.
+// Unlike regular tasks, calling Resolve() on a resolved
+// task overwrites the result instead of being ignored.
+// Await() returns the latest value, and AwaitNext()
+// waits for the next overwrite.
+// A cancelled task still needs a Reset() to be resolved again.
+// Example:
+//
+//	config := NewLatestTask[Config]()
+//	config.Resolve(loadConfig())
+//	// ...later, on reload
+//	config.Resolve(loadConfig())
+func NewLatestTask[T any](opts ...TaskOption) Task[T] {
+	t := newTaskWith[T](opts)
+	t.overwrite = true
+	return t
+}
+
+// Creates n new tasks, e.g. one per entity.
+// Example:
+//
+//	loaded := NewTasks[Chunk](len(coords))
+//	// ...
+//	chunks := AwaitAllSlice(loaded)
+func NewTasks[T any](n int, opts ...TaskOption) []Task[T] {
+	tasks := make([]Task[T], n)
+	for i := range tasks {
+		tasks[i] = newTaskWith[T](opts)
+	}
+	return tasks
 }
 
 // Start the function fn, and returns a task.
@@ -150,58 +540,491 @@ func Start[T any](fn func() T) Task[T] {
 	return task
 }
 
+// Starts n replicas of fn, each given its index,
+// and returns their tasks in the same order.
+// Example:
+//
+//	parts := StartN(4, func(i int) []byte {
+//	  return download(url, i*chunkSize, chunkSize)
+//	})
+//	results := AwaitAllSlice(parts)
+func StartN[T any](n int, fn func(i int) T) []Task[T] {
+	tasks := make([]Task[T], n)
+	for i := range tasks {
+		i := i
+		tasks[i] = Start(func() T { return fn(i) })
+	}
+	return tasks
+}
+
+// Same as Start(), but fn is started after the delay.
+// Cancelling the task before then skips fn altogether.
+// Example:
+//
+//	autosave := StartAfter(time.Minute, save)
+//	// the user saved manually in the meantime
+//	autosave.Cancel()
+func StartAfter[T any](d time.Duration, fn func() T) Task[T] {
+	task := newTask[T]()
+	timer := time.AfterFunc(d, func() {
+		if !task.IsDone() {
+			task.Resolve(fn())
+		}
+	})
+	task.OnCancel(func() { timer.Stop() })
+	return task
+}
+
 func (task *taskImpl[T]) ID() int64 {
 	return task.id
 }
 
+func (task *taskImpl[T]) Name() string {
+	return task.opts.name
+}
+
+func (task *taskImpl[T]) SetMeta(key, value any) {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	if task.meta == nil {
+		task.meta = map[any]any{}
+	}
+	task.meta[key] = value
+}
+
+func (task *taskImpl[T]) Meta(key any) any {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+	return task.meta[key]
+}
+
+// Returns a copy of the metadata.
+func (task *taskImpl[T]) metadata() map[any]any {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	meta := make(map[any]any, len(task.meta))
+	for key, value := range task.meta {
+		meta[key] = value
+	}
+	return meta
+}
+
 func (task *taskImpl[T]) Resolve(value T) {
+	task.resolve(value)
+}
+
+func (task *taskImpl[T]) TryResolve(value T) bool {
+	return task.resolve(value)
+}
+
+func (task *taskImpl[T]) resolve(value T) bool {
+	callbacks, ok := task.resolveDeferred(value)
+	runCallbacks(callbacks)
+	return ok
+}
+
+// Same as resolve(), but returns the callbacks of the
+// settlement instead of running them, for callers that
+// hold a lock of their own while resolving.
+// The waiters are woken up right away.
+func (task *taskImpl[T]) resolveDeferred(value T) ([]func(), bool) {
 	task.resolveMu.Lock()
 	defer task.resolveMu.Unlock()
 
-	if task.status != taskPending {
-		return
+	if !task.prepareResolve() {
+		return nil, false
 	}
 
 	task.value = value
 	task.status = taskResolved
-	task.awaitMu.Unlock()
+	return task.settle(), true
+}
+
+func (task *taskImpl[T]) ResolveWith(fn func() T) bool {
+	task.resolveMu.Lock()
+
+	if !task.prepareResolve() {
+		task.resolveMu.Unlock()
+		return false
+	}
+
+	computed := false
+	defer func() {
+		if !computed {
+			task.resolveMu.Unlock()
+		}
+	}()
+	task.value = fn()
+	computed = true
+
+	task.status = taskResolved
+	task.settleAndUnlock()
 
+	return true
 }
 
 func (task *taskImpl[T]) Error() error {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	if task.err == nil && task.status == taskCanceled {
+		return task.cancelErr
+	}
 	return task.err
 }
 
+func (task *taskImpl[T]) SetCancelError(err error) {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+	task.cancelErr = err
+}
+
+// Returns the error thrown by Await() with SetPanic(true).
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) panicError() error {
+	if task.cancelErr != nil {
+		return task.cancelErr
+	}
+	return ErrCancelled
+}
+
 func (task *taskImpl[T]) Fail(err error) {
-	if task.cancel() {
-		task.err = err
+	if err != nil && task.opts.stackTrace {
+		err = &TaskError{
+			ID:    task.id,
+			Name:  task.opts.name,
+			Err:   err,
+			Stack: debug.Stack(),
+		}
 	}
+	task.cancel(err)
 }
 
 func (task *taskImpl[T]) Cancel() {
-	task.cancel()
+	task.cancel(nil)
 }
 
-func (task *taskImpl[T]) cancel() bool {
+func (task *taskImpl[T]) TryCancel() bool {
+	return task.cancel(nil)
+}
+
+func (task *taskImpl[T]) cancel(err error) bool {
 	task.resolveMu.Lock()
-	defer task.resolveMu.Unlock()
 
-	if task.status != taskPending {
+	if !task.prepareSettle() {
+		task.resolveMu.Unlock()
 		return false
 	}
 
+	task.markCancelled(err)
+	task.settleAndUnlock()
+
+	return true
+}
+
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) markCancelled(err error) {
+	task.value = task.defaultValue
 	task.status = taskCanceled
-	task.awaitMu.Unlock()
+	task.failed = err != nil
+	if err != nil || !task.opts.stickyError {
+		task.err = err
+	}
+}
 
+// Checks if the task can be settled, resetting
+// auto-reset tasks that are already done.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) prepareSettle() bool {
+	if task.status == taskPending {
+		return true
+	}
+	if !task.autoReset && !(task.cancelResolved && task.status == taskResolved) {
+		return false
+	}
+	task.reset()
 	return true
 }
 
+// Same as prepareSettle(), but also allows
+// overwriting the result of latest-value tasks.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) prepareResolve() bool {
+	if task.overwrite && task.status == taskResolved {
+		task.reset()
+		return true
+	}
+	return task.prepareSettle()
+}
+
+// Reports whether Resolve() would settle the task now.
+func (task *taskImpl[T]) canResolve() bool {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+	return task.status == taskPending || task.autoReset ||
+		task.status == taskResolved && (task.overwrite || task.cancelResolved)
+}
+
+// Wakes up the waiters, then runs the callbacks
+// registered for this settlement outside the lock.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) settleAndUnlock() {
+	callbacks := task.settle()
+	task.resolveMu.Unlock()
+	runCallbacks(callbacks)
+}
+
+// Wakes up the waiters, and returns the callbacks
+// to be run once the lock is released.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) settle() []func() {
+	if task.opts.timing {
+		task.recordSettlement()
+	}
+	if task.status == taskResolved && debugMode.Load() {
+		task.recordResolution()
+	}
+	task.notifyChange()
+	var callbacks []func()
+	if len(task.pendingChanges) > 0 {
+		callbacks = append(callbacks, task.deliverChanges)
+	}
+
+	if task.done != nil {
+		task.done.value = task.value
+		task.done.ok = task.status == taskResolved
+		task.done.panics = task.panics
+		task.done.err = task.panicError()
+		close(task.done.ch)
+		if len(task.done.turns) > 0 {
+			close(task.done.turns[0])
+		}
+	}
+	task.settled = task.done
+	task.done = nil
+	task.cycle++
+
+	if task.status == taskResolved && task.opts.checkpointer != nil {
+		callbacks = append(callbacks, task.saveCheckpoint(task.value))
+	}
+	if task.status == taskResolved && !task.awaited && len(task.watchers) == 0 {
+		if hook := orphanHook.Load(); hook != nil {
+			id, name := task.id, task.opts.name
+			callbacks = append(callbacks, func() { (*hook)(id, name) })
+		}
+	}
+	if task.status == taskResolved && !task.awaited {
+		if hook := idleHook.Load(); hook != nil {
+			callbacks = append(callbacks, task.watchIdle(hook))
+		}
+	}
+	if task.status == taskResolved {
+		for _, fn := range task.watchers {
+			fn, value := *fn, task.value
+			callbacks = append(callbacks, func() { fn(value) })
+		}
+	}
+	if task.status == taskCanceled {
+		callbacks = append(callbacks, task.onCancel...)
+	}
+	for i := len(task.deferred) - 1; i >= 0; i-- {
+		callbacks = append(callbacks, task.deferred[i])
+	}
+	task.onCancel = nil
+	task.deferred = nil
+
+	return callbacks
+}
+
+func runCallbacks(callbacks []func()) {
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+func (task *taskImpl[T]) OnResolve(fn func()) func() {
+	return task.watch(func(T) { fn() })
+}
+
+func (task *taskImpl[T]) watch(fn func(T)) func() {
+	watcher := &fn
+	task.resolveMu.Lock()
+	task.watchers = append(task.watchers, watcher)
+	task.resolveMu.Unlock()
+
+	return func() {
+		task.resolveMu.Lock()
+		defer task.resolveMu.Unlock()
+		for i, w := range task.watchers {
+			if w == watcher {
+				task.watchers = append(task.watchers[:i:i], task.watchers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (task *taskImpl[T]) OnCancel(fn func()) {
+	task.resolveMu.Lock()
+	switch task.status {
+	case taskPending:
+		task.onCancel = append(task.onCancel, fn)
+		task.resolveMu.Unlock()
+	case taskCanceled:
+		task.resolveMu.Unlock()
+		fn()
+	default:
+		task.resolveMu.Unlock()
+	}
+}
+
+func (task *taskImpl[T]) Defer(fn func()) {
+	task.resolveMu.Lock()
+	if task.status == taskPending {
+		task.deferred = append(task.deferred, fn)
+		task.resolveMu.Unlock()
+		return
+	}
+	task.resolveMu.Unlock()
+	fn()
+}
+
+func (task *taskImpl[T]) SetDefaultValue(value T) {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	task.defaultValue = value
+	if task.status != taskResolved {
+		task.value = value
+	}
+}
+
 func (task *taskImpl[T]) IsCancelled() bool {
 	task.resolveMu.Lock()
 	defer task.resolveMu.Unlock()
 	return task.status == taskCanceled
 }
 
+func (task *taskImpl[T]) Status() Status {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+	return task.currentStatus()
+}
+
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) currentStatus() Status {
+	switch {
+	case task.status == taskResolved:
+		return Resolved
+	case task.status == taskCanceled && task.failed:
+		return Failed
+	case task.status == taskCanceled:
+		return Cancelled
+	}
+	return Pending
+}
+
+func (task *taskImpl[T]) StateChanges() *Stream[Status] {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	stream := NewStream[Status](stateChangesBuffer)
+	task.changes = append(task.changes, stream)
+	return stream
+}
+
+// Queues the current status for the changes streams.
+// Must be called with resolveMu held, followed by
+// deliverChanges() once unlocked.
+func (task *taskImpl[T]) notifyChange() {
+	if len(task.changes) == 0 {
+		return
+	}
+	task.pendingChanges = append(task.pendingChanges, task.currentStatus())
+}
+
+// Sends the queued statuses to the changes streams.
+// Only one goroutine sends at a time, so that the
+// statuses are received in order.
+// Must be called without resolveMu held, since sending
+// may run the callbacks of the receivers.
+func (task *taskImpl[T]) deliverChanges() {
+	task.resolveMu.Lock()
+	if task.deliveringChanges {
+		task.resolveMu.Unlock()
+		return
+	}
+	task.deliveringChanges = true
+	for len(task.pendingChanges) > 0 {
+		statuses := task.pendingChanges
+		streams := append([]*Stream[Status](nil), task.changes...)
+		task.pendingChanges = nil
+		task.resolveMu.Unlock()
+
+		var closed []*Stream[Status]
+		for _, stream := range streams {
+			for _, status := range statuses {
+				if !stream.offer(status) {
+					closed = append(closed, stream)
+					break
+				}
+			}
+		}
+
+		task.resolveMu.Lock()
+		for _, stream := range closed {
+			for i, s := range task.changes {
+				if s == stream {
+					task.changes = append(task.changes[:i:i], task.changes[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	task.deliveringChanges = false
+	task.resolveMu.Unlock()
+}
+
+func (task *taskImpl[T]) Timestamps() Timestamps {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+	return task.times
+}
+
+func (task *taskImpl[T]) Duration() time.Duration {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	start := task.times.Created
+	if !task.times.Reset.IsZero() {
+		start = task.times.Reset
+	}
+	if start.IsZero() {
+		return 0
+	}
+	switch {
+	case !task.times.Resolved.IsZero():
+		return task.times.Resolved.Sub(start)
+	case !task.times.Cancelled.IsZero():
+		return task.times.Cancelled.Sub(start)
+	}
+	return time.Since(start)
+}
+
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) recordSettlement() {
+	now := time.Now()
+	if task.status == taskResolved {
+		task.times.Resolved = now
+		task.times.Cancelled = time.Time{}
+	} else {
+		task.times.Cancelled = now
+		task.times.Resolved = time.Time{}
+	}
+}
+
 func (task *taskImpl[T]) IsDone() bool {
 	task.resolveMu.Lock()
 	defer task.resolveMu.Unlock()
@@ -209,38 +1032,224 @@ func (task *taskImpl[T]) IsDone() bool {
 }
 
 func (task *taskImpl[T]) Await() (T, bool) {
+	if task.autoReset {
+		return task.AwaitNext()
+	}
+
+	task.resolveMu.Lock()
+	task.awaited = true
+	if task.status == taskPending {
+		done := task.doneWait()
+		return task.block(done)
+	}
+	defer task.resolveMu.Unlock()
+
+	if task.status == taskCanceled && task.panics {
+		panic(task.panicError())
+	}
+
+	return task.value, task.status == taskResolved
+}
+
+func (task *taskImpl[T]) AwaitNext() (T, bool) {
 	task.resolveMu.Lock()
+	done := task.nextWait()
 	if task.status == taskPending {
+		task.awaited = true
+	}
+	return task.block(done)
+}
+
+func (task *taskImpl[T]) AwaitWithTicker(interval time.Duration, onTick func(time.Duration)) (T, bool) {
+	if interval <= 0 {
+		return task.Await()
+	}
+	task.resolveMu.Lock()
+	if task.status != taskPending && !task.autoReset {
 		task.resolveMu.Unlock()
-		task.awaitMu.RLock()
-		//lint:ignore SA2001 Donkeys
-		task.awaitMu.RUnlock()
-	} else {
+		return task.Await()
+	}
+	task.awaited = true
+	done := task.nextWait()
+	done.readers.Add(1)
+	task.waiting.Add(1)
+	task.resolveMu.Unlock()
+
+	defer task.waiting.Add(-1)
+	defer done.readers.Done()
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done.ch:
+			return done.result()
+		case now := <-ticker.C:
+			onTick(now.Sub(start))
+		}
+	}
+}
+
+// Unlocks the task, and blocks until the wait is settled.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) block(done *taskWait[T]) (T, bool) {
+	done.readers.Add(1)
+	turn := make(chan struct{})
+	done.turns = append(done.turns, turn)
+	next := len(done.turns)
+	task.waiting.Add(1)
+	task.resolveMu.Unlock()
+
+	defer task.waiting.Add(-1)
+	return done.wait(turn, next)
+}
+
+func (task *taskImpl[T]) WaiterCount() int {
+	return int(task.waiting.Load())
+}
+
+// Blocks until the wait is settled and it's the given
+// turn, then passes the turn after it on, and returns
+// the result. next is the index of the following turn.
+// readers must have been incremented, and the turn
+// taken, with resolveMu held.
+func (w *taskWait[T]) wait(turn chan struct{}, next int) (T, bool) {
+	defer w.readers.Done()
+	<-turn
+	defer func() {
+		if next < len(w.turns) {
+			close(w.turns[next])
+		}
+	}()
+	return w.result()
+}
+
+func (w *taskWait[T]) result() (T, bool) {
+	if !w.ok && w.panics {
+		panic(w.err)
+	}
+	return w.value, w.ok
+}
+
+func (task *taskImpl[T]) AwaitAbortable() (<-chan Result[T], func()) {
+	result := make(chan Result[T], 1)
+
+	task.resolveMu.Lock()
+	task.awaited = true
+	if task.status != taskPending && !task.autoReset {
+		result <- Result[T]{task.value, task.status == taskResolved}
+		close(result)
 		task.resolveMu.Unlock()
+		return result, func() {}
+	}
+	done := task.nextWait()
+	task.resolveMu.Unlock()
+
+	aborted := make(chan struct{})
+	var once sync.Once
+	go func() {
+		defer close(result)
+		select {
+		case <-done.ch:
+			result <- Result[T]{done.value, done.ok}
+		case <-aborted:
+		}
+	}()
+
+	return result, func() {
+		once.Do(func() { close(aborted) })
 	}
+}
 
+func (task *taskImpl[T]) Value() (T, bool) {
 	task.resolveMu.Lock()
 	defer task.resolveMu.Unlock()
+	task.awaited = true
 
-	return task.value, task.status == taskResolved
+	if task.status != taskResolved {
+		var empty T
+		return empty, false
+	}
+	return task.value, true
 }
 
-func (task *taskImpl[T]) Reset() bool {
+func (task *taskImpl[T]) SetPanic(enabled bool) {
 	task.resolveMu.Lock()
 	defer task.resolveMu.Unlock()
+	task.panics = enabled
+}
+
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) doneWait() *taskWait[T] {
+	if task.done == nil {
+		task.done = &taskWait[T]{ch: make(chan struct{})}
+	}
+	return task.done
+}
 
+// Returns the wait for the next settlement,
+// skipping the current one if the task is already done.
+// Must be called with resolveMu held.
+func (task *taskImpl[T]) nextWait() *taskWait[T] {
 	if task.status == taskPending {
+		return task.doneWait()
+	}
+	if task.nextDone == nil {
+		task.nextDone = &taskWait[T]{ch: make(chan struct{})}
+	}
+	return task.nextDone
+}
+
+func (task *taskImpl[T]) Reset() bool {
+	task.resolveMu.Lock()
+
+	if task.status == taskPending {
+		task.resolveMu.Unlock()
 		return false
 	}
 
-	task.awaitMu.Lock()
-	task.status = taskPending
-	task.value = task.defaultValue
-	task.err = nil
+	task.reset()
+	task.resolveMu.Unlock()
+	task.deliverChanges()
 
 	return true
 }
 
+func (task *taskImpl[T]) ResetAfterAwaiters() bool {
+	task.resolveMu.Lock()
+	settled := task.settled
+	task.resolveMu.Unlock()
+
+	if settled != nil {
+		settled.readers.Wait()
+	}
+	return task.Reset()
+}
+
+// Must be called with resolveMu held, followed by
+// deliverChanges() once unlocked.
+func (task *taskImpl[T]) reset() {
+	task.status = taskPending
+	task.failed = false
+	task.awaited = false
+	task.value = task.defaultValue
+	if !task.opts.stickyError {
+		task.err = nil
+	}
+	if !task.opts.stickyPanic {
+		task.panics = false
+	}
+	task.done = task.nextDone
+	task.nextDone = nil
+	if task.opts.timing {
+		task.times.Reset = time.Now()
+		task.times.Resolved = time.Time{}
+		task.times.Cancelled = time.Time{}
+	}
+	task.notifyChange()
+}
+
 // Waits for all tasks or awaitables to finish.
 // Returns nil for tasks that have been cancelled.
 // The tasks can have different result types.
@@ -290,6 +1299,299 @@ func Await5[A any, B any, C any, D any, E any](
 		asPointer(t5.Await())
 }
 
+// Same behaviour with Await2()
+func Await6[A any, B any, C any, D any, E any, F any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+) (*A, *B, *C, *D, *E, *F) {
+	return asPointer(t1.Await()),
+		asPointer(t2.Await()),
+		asPointer(t3.Await()),
+		asPointer(t4.Await()),
+		asPointer(t5.Await()),
+		asPointer(t6.Await())
+}
+
+// Same behaviour with Await2()
+func Await7[A any, B any, C any, D any, E any, F any, G any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+) (*A, *B, *C, *D, *E, *F, *G) {
+	return asPointer(t1.Await()),
+		asPointer(t2.Await()),
+		asPointer(t3.Await()),
+		asPointer(t4.Await()),
+		asPointer(t5.Await()),
+		asPointer(t6.Await()),
+		asPointer(t7.Await())
+}
+
+// Same behaviour with Await2()
+func Await8[A any, B any, C any, D any, E any, F any, G any, H any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+	t8 Awaitable[H],
+) (*A, *B, *C, *D, *E, *F, *G, *H) {
+	return asPointer(t1.Await()),
+		asPointer(t2.Await()),
+		asPointer(t3.Await()),
+		asPointer(t4.Await()),
+		asPointer(t5.Await()),
+		asPointer(t6.Await()),
+		asPointer(t7.Await()),
+		asPointer(t8.Await())
+}
+
+// Same behaviour with Await2()
+func Await9[A any, B any, C any, D any, E any, F any, G any, H any, I any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+	t8 Awaitable[H],
+	t9 Awaitable[I],
+) (*A, *B, *C, *D, *E, *F, *G, *H, *I) {
+	return asPointer(t1.Await()),
+		asPointer(t2.Await()),
+		asPointer(t3.Await()),
+		asPointer(t4.Await()),
+		asPointer(t5.Await()),
+		asPointer(t6.Await()),
+		asPointer(t7.Await()),
+		asPointer(t8.Await()),
+		asPointer(t9.Await())
+}
+
+// Same behaviour with Await2()
+func Await10[A any, B any, C any, D any, E any, F any, G any, H any, I any, J any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+	t8 Awaitable[H],
+	t9 Awaitable[I],
+	t10 Awaitable[J],
+) (*A, *B, *C, *D, *E, *F, *G, *H, *I, *J) {
+	return asPointer(t1.Await()),
+		asPointer(t2.Await()),
+		asPointer(t3.Await()),
+		asPointer(t4.Await()),
+		asPointer(t5.Await()),
+		asPointer(t6.Await()),
+		asPointer(t7.Await()),
+		asPointer(t8.Await()),
+		asPointer(t9.Await()),
+		asPointer(t10.Await())
+}
+
+// Same as Await2(), but returns the values directly,
+// along with an error joining the errors of the tasks
+// that were cancelled or failed, each prefixed with the
+// task's index. Values of unresolved tasks are zero values.
+// Example:
+//
+//	user, posts, err := Await2E(fetchUser(id), fetchPosts(id))
+//	if err != nil {
+//	  return err // e.g. task 1: permission denied
+//	}
+func Await2E[A any, B any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+) (A, B, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	return a, b, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await3E[A any, B any, C any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+) (A, B, C, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	return a, b, c, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await4E[A any, B any, C any, D any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+) (A, B, C, D, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	return a, b, c, d, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await5E[A any, B any, C any, D any, E any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+) (A, B, C, D, E, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	e := awaitE(&errs, 4, t5)
+	return a, b, c, d, e, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await6E[A any, B any, C any, D any, E any, F any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+) (A, B, C, D, E, F, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	e := awaitE(&errs, 4, t5)
+	f := awaitE(&errs, 5, t6)
+	return a, b, c, d, e, f, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await7E[A any, B any, C any, D any, E any, F any, G any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+) (A, B, C, D, E, F, G, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	e := awaitE(&errs, 4, t5)
+	f := awaitE(&errs, 5, t6)
+	g := awaitE(&errs, 6, t7)
+	return a, b, c, d, e, f, g, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await8E[A any, B any, C any, D any, E any, F any, G any, H any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+	t8 Awaitable[H],
+) (A, B, C, D, E, F, G, H, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	e := awaitE(&errs, 4, t5)
+	f := awaitE(&errs, 5, t6)
+	g := awaitE(&errs, 6, t7)
+	h := awaitE(&errs, 7, t8)
+	return a, b, c, d, e, f, g, h, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await9E[A any, B any, C any, D any, E any, F any, G any, H any, I any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+	t8 Awaitable[H],
+	t9 Awaitable[I],
+) (A, B, C, D, E, F, G, H, I, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	e := awaitE(&errs, 4, t5)
+	f := awaitE(&errs, 5, t6)
+	g := awaitE(&errs, 6, t7)
+	h := awaitE(&errs, 7, t8)
+	i := awaitE(&errs, 8, t9)
+	return a, b, c, d, e, f, g, h, i, errors.Join(errs...)
+}
+
+// Same behaviour with Await2E()
+func Await10E[A any, B any, C any, D any, E any, F any, G any, H any, I any, J any](
+	t1 Awaitable[A],
+	t2 Awaitable[B],
+	t3 Awaitable[C],
+	t4 Awaitable[D],
+	t5 Awaitable[E],
+	t6 Awaitable[F],
+	t7 Awaitable[G],
+	t8 Awaitable[H],
+	t9 Awaitable[I],
+	t10 Awaitable[J],
+) (A, B, C, D, E, F, G, H, I, J, error) {
+	var errs []error
+	a := awaitE(&errs, 0, t1)
+	b := awaitE(&errs, 1, t2)
+	c := awaitE(&errs, 2, t3)
+	d := awaitE(&errs, 3, t4)
+	e := awaitE(&errs, 4, t5)
+	f := awaitE(&errs, 5, t6)
+	g := awaitE(&errs, 6, t7)
+	h := awaitE(&errs, 7, t8)
+	i := awaitE(&errs, 8, t9)
+	j := awaitE(&errs, 9, t10)
+	return a, b, c, d, e, f, g, h, i, j, errors.Join(errs...)
+}
+
+func awaitE[T any](errs *[]error, index int, t Awaitable[T]) T {
+	value, ok := t.Await()
+	if !ok {
+		*errs = append(*errs, indexedError(index, t))
+	}
+	return value
+}
+
 // Same behaviour with Await2(), except
 // the result is not return, and the tasks must have
 // the same types.
@@ -306,27 +1608,104 @@ func AwaitAll[T any](tasks ...Awaitable[T]) {
 	}
 }
 
+// Same as AwaitAll(), but returns an error joining the
+// errors of all tasks that were cancelled or failed,
+// each prefixed with the task's index.
+// Cancelled tasks without an error are reported as ErrCancelled.
+// Returns nil if all tasks were resolved.
+// Example:
+//
+//	if err := AwaitAllErr(uploads...); err != nil {
+//	  log.Println(err) // task 2: connection reset
+//	}
+func AwaitAllErr[T any](tasks ...Awaitable[T]) error {
+	var errs []error
+	for i, t := range tasks {
+		if _, ok := t.Await(); !ok {
+			errs = append(errs, indexedError(i, t))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Same as AwaitAll(), but takes a slice of tasks, and
+// returns their results in the same order.
+// Example:
+//
+//	for i, r := range AwaitAllSlice(loaded) {
+//	  if r.OK { world.Set(i, r.Value) }
+//	}
+func AwaitAllSlice[T any](tasks []Task[T]) []Result[T] {
+	results := make([]Result[T], len(tasks))
+	for i, t := range tasks {
+		value, ok := t.Await()
+		results[i] = Result[T]{value, ok}
+	}
+	return results
+}
+
+// Same as AwaitAllSlice(), but also returns an error
+// joining the errors of the tasks that were cancelled
+// or failed, each prefixed with the task's index, as
+// with AwaitAllErr().
+// Example:
+//
+//	results, err := AwaitAllSliceErr(loaded)
+//	if err != nil {
+//	  log.Println(err) // task 3: file not found
+//	}
+func AwaitAllSliceErr[T any](tasks []Task[T]) ([]Result[T], error) {
+	results := make([]Result[T], len(tasks))
+	var errs []error
+	for i, t := range tasks {
+		value, ok := t.Await()
+		results[i] = Result[T]{value, ok}
+		if !ok {
+			errs = append(errs, indexedError(i, t))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
 // Waits for one task to complete.
 // It blocks until at least one task has
 // been Resolved() or Cancel().
+// Returns right away if a task is already done.
+// Cold inputs, i.e. Deferred() tasks and awaitables
+// that aren't tasks, are awaited after the other tasks,
+// in order, and only while no task is done yet,
+// so they may never be started.
 //
 //	var task1 = NewTask[int]()
 //	var task2 = NewTask[int]()
 //	var task3 AwaitableFn[int]= func() (string, bool) { return 0, true }
 //	AwaitSome(task1, task2, task3)
 func AwaitSome[T any](tasks ...Awaitable[T]) {
-	blocker := AllocTask[Void]()
-	defer FreeTask(blocker)
-
+	var cold []Awaitable[T]
+	var hot []Awaitable[T]
 	for _, t := range tasks {
+		task, ok := t.(AnyTask)
+		if ok && task.IsDone() {
+			return
+		}
+		if _, deferred := t.(interface{ start() }); !ok || deferred {
+			cold = append(cold, t)
+		} else {
+			hot = append(hot, t)
+		}
+	}
+
+	// Not pooled, the goroutines may still use
+	// it after AwaitSome() returns.
+	blocker := newTask[Void]()
+
+	for _, t := range append(hot, cold...) {
 		if blocker.IsDone() {
 			break
 		}
 		go func(t Awaitable[T]) {
 			t.Await()
-			if !blocker.IsDone() {
-				blocker.Resolve(None)
-			}
+			blocker.TryResolve(None)
 		}(t)
 	}
 
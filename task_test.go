@@ -1,11 +1,16 @@
 package quest_test
 
 import (
+	"errors"
 	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/nvlled/mud"
 	"github.com/nvlled/quest"
 )
 
@@ -221,6 +226,25 @@ func TestAwaitSome(t *testing.T) {
 	}
 }
 
+func TestAwaitSomeLateInputs(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		t1 := quest.NewTask[int]()
+		t2 := quest.NewTask[int]()
+		go t1.Resolve(1)
+		go t2.Resolve(2)
+		quest.AwaitSome[int](t1, t2)
+
+		// the input that finished last must not
+		// touch tasks allocated afterwards
+		task := quest.AllocTask[quest.Void]()
+		randomSleep()
+		if task.IsDone() {
+			t.Fatal("reused task should not be resolved")
+		}
+		quest.FreeTask(task)
+	}
+}
+
 func TestReset(t *testing.T) {
 	t1 := quest.NewTask[int]()
 
@@ -272,3 +296,848 @@ func randomSleep() {
 	ms := 1 + rand.Int31n(999)
 	time.Sleep(time.Duration(ms * int32(time.Microsecond)))
 }
+
+func TestMultiTask(t *testing.T) {
+	t1 := quest.NewMultiTask[int]()
+	t1.Resolve(-1)
+
+	results := make(chan int)
+	go func() {
+		for i := 0; i < 3; i++ {
+			n, _ := t1.Await()
+			results <- n
+		}
+	}()
+
+	for i := 0; i < 3; i++ {
+		// wait until the consumer is blocked again
+		time.Sleep(5 * time.Millisecond)
+		t1.Resolve(i)
+		if n := <-results; n != i {
+			t.Errorf("expected %v, got %v", i, n)
+		}
+	}
+}
+
+func TestAwaitNext(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	t1.Resolve(1)
+
+	done := make(chan int)
+	go func() {
+		n, _ := t1.AwaitNext()
+		done <- n
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	select {
+	case <-done:
+		t.Error("should not return the stale result")
+	default:
+	}
+
+	t1.Reset()
+	t1.Resolve(2)
+	if n := <-done; n != 2 {
+		t.Errorf("expected 2, got %v", n)
+	}
+}
+
+func TestAwaitWithTicker(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	time.AfterFunc(25*time.Millisecond, func() { t1.Resolve(3) })
+
+	var ticks []time.Duration
+	n, ok := t1.AwaitWithTicker(5*time.Millisecond, func(elapsed time.Duration) {
+		ticks = append(ticks, elapsed)
+	})
+	if n != 3 || !ok {
+		t.Errorf("expected 3, got %v", n)
+	}
+	if len(ticks) < 2 {
+		t.Errorf("expected several ticks, got %v", ticks)
+	}
+	for i := 1; i < len(ticks); i++ {
+		if ticks[i] <= ticks[i-1] {
+			t.Errorf("elapsed times should increase: %v", ticks)
+		}
+	}
+
+	ticks = nil
+	if n, _ := t1.AwaitWithTicker(time.Millisecond, func(time.Duration) { ticks = append(ticks, 0) }); n != 3 || ticks != nil {
+		t.Error("a done task should return right away")
+	}
+
+	t2 := quest.NewTask[int]()
+	time.AfterFunc(time.Millisecond, func() { t2.Resolve(4) })
+	if n, _ := t2.AwaitWithTicker(0, func(time.Duration) { ticks = append(ticks, 0) }); n != 4 || ticks != nil {
+		t.Error("a zero interval should not tick")
+	}
+}
+
+func TestSetDefaultValue(t *testing.T) {
+	t1 := quest.NewTask[string]()
+	t1.SetDefaultValue("none")
+
+	t1.Cancel()
+	if v, ok := t1.Await(); v != "none" || ok {
+		t.Errorf("expected default value, got %v", v)
+	}
+
+	t1.Reset()
+	t1.Resolve("apples")
+	if v, ok := t1.Await(); v != "apples" || !ok {
+		t.Errorf("expected apples, got %v", v)
+	}
+
+	t1.Reset()
+	t1.Fail(errors.New("nope"))
+	if v, _ := t1.Await(); v != "none" {
+		t.Errorf("expected default value, got %v", v)
+	}
+}
+
+func TestTryResolve(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	wins := atomic.Int32{}
+
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			if t1.TryResolve(i) {
+				wins.Add(1)
+			}
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	if wins.Load() != 1 {
+		t.Errorf("expected exactly one winner, got %v", wins.Load())
+	}
+	if t1.TryCancel() {
+		t.Error("cancel should have no effect on a resolved task")
+	}
+}
+
+func TestResolveWith(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	calls := 0
+	compute := func() int {
+		calls++
+		return 100
+	}
+
+	if !t1.ResolveWith(compute) {
+		t.Error("should resolve a pending task")
+	}
+	if t1.ResolveWith(compute) {
+		t.Error("should not resolve a done task")
+	}
+	if calls != 1 {
+		t.Errorf("expected fn to be called once, got %v", calls)
+	}
+	if n, _ := t1.Await(); n != 100 {
+		t.Errorf("expected 100, got %v", n)
+	}
+}
+
+func TestLatestTask(t *testing.T) {
+	t1 := quest.NewLatestTask[int]()
+	t1.Resolve(1)
+
+	next := quest.Start(func() int {
+		n, _ := t1.AwaitNext()
+		return n
+	})
+	time.Sleep(5 * time.Millisecond)
+
+	if !t1.TryResolve(2) {
+		t.Error("resolve should overwrite the value")
+	}
+	if n, _ := t1.Await(); n != 2 {
+		t.Errorf("expected 2, got %v", n)
+	}
+	if n, _ := next.Await(); n != 2 {
+		t.Errorf("expected AwaitNext to get 2, got %v", n)
+	}
+
+	if t1.TryCancel() {
+		t.Error("cancel should not overwrite a resolved value")
+	}
+
+	t1.Reset()
+	t1.Cancel()
+	if t1.TryResolve(3) {
+		t.Error("resolve should not overwrite a cancelled task")
+	}
+}
+
+func TestStickyReset(t *testing.T) {
+	err := errors.New("nope")
+
+	t1 := quest.NewTask[int]()
+	t1.SetPanic(true)
+	t1.Fail(err)
+	t1.Reset()
+	if t1.Error() != nil {
+		t.Error("error should be cleared on reset")
+	}
+	t1.Cancel()
+	if _, ok := t1.Await(); ok {
+		t.Error("panic flag should be cleared on reset")
+	}
+
+	t2 := quest.NewTask[int](quest.WithStickyError(), quest.WithStickyPanic())
+	t2.SetPanic(true)
+	t2.Fail(err)
+	t2.Reset()
+	if t2.Error() != err {
+		t.Error("error should be preserved on reset")
+	}
+	t2.Cancel()
+	if t2.Error() != err {
+		t.Error("error should only be replaced by Fail()")
+	}
+	defer func() {
+		if recover() != quest.ErrCancelled {
+			t.Error("panic flag should be preserved on reset")
+		}
+	}()
+	t2.Await()
+}
+
+func TestValue(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	if _, ok := t1.Value(); ok {
+		t.Error("pending task should have no value")
+	}
+	t1.Resolve(5)
+	if n, ok := t1.Value(); n != 5 || !ok {
+		t.Errorf("expected 5, got %v", n)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	if t1.Status() != quest.Pending {
+		t.Errorf("expected pending, got %v", t1.Status())
+	}
+	t1.Resolve(1)
+	if t1.Status() != quest.Resolved {
+		t.Errorf("expected resolved, got %v", t1.Status())
+	}
+	t1.Reset()
+	t1.Cancel()
+	if t1.Status() != quest.Cancelled {
+		t.Errorf("expected cancelled, got %v", t1.Status())
+	}
+	t1.Reset()
+	t1.Fail(errors.New("nope"))
+	if t1.Status() != quest.Failed {
+		t.Errorf("expected failed, got %v", t1.Status())
+	}
+}
+
+func TestOnCancel(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	calls := 0
+	t1.OnCancel(func() { calls++ })
+
+	t1.Fail(errors.New("nope"))
+	t1.Cancel()
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %v", calls)
+	}
+
+	t1.OnCancel(func() { calls++ })
+	if calls != 2 {
+		t.Error("should be called immediately on a cancelled task")
+	}
+
+	t2 := quest.NewTask[int]()
+	t2.OnCancel(func() { calls++ })
+	t2.Resolve(1)
+	t2.Reset()
+	t2.Cancel()
+	if calls != 2 {
+		t.Error("should be discarded when the task is resolved")
+	}
+}
+
+func TestDefer(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	order := []int{}
+	t1.Defer(func() { order = append(order, 1) })
+	t1.Defer(func() { order = append(order, 2) })
+	t1.OnCancel(func() { order = append(order, 0) })
+
+	t1.Cancel()
+	if len(order) != 3 || order[0] != 0 || order[1] != 2 || order[2] != 1 {
+		t.Errorf("wrong call order: %v", order)
+	}
+
+	t2 := quest.NewTask[int]()
+	called := false
+	t2.Defer(func() { called = true })
+	t2.Resolve(1)
+	if !called {
+		t.Error("should be called on resolve")
+	}
+}
+
+func TestAwaitAbortable(t *testing.T) {
+	t1 := quest.NewTask[int]()
+
+	result, abort := t1.AwaitAbortable()
+	abort()
+	abort()
+	if _, ok := <-result; ok {
+		t.Error("aborted wait should not receive a result")
+	}
+	if t1.IsDone() {
+		t.Error("aborting should not cancel the task")
+	}
+
+	result, _ = t1.AwaitAbortable()
+	t1.Resolve(7)
+	if r := <-result; r.Value != 7 || !r.OK {
+		t.Errorf("expected 7, got %v", r)
+	}
+}
+
+func TestAwaitAllErr(t *testing.T) {
+	err := errors.New("nope")
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[int]()
+	t3 := quest.NewTask[int]()
+	t1.Resolve(1)
+	t2.Fail(err)
+	t3.Cancel()
+
+	joined := quest.AwaitAllErr[int](t1, t2, t3)
+	if !errors.Is(joined, err) || !errors.Is(joined, quest.ErrCancelled) {
+		t.Errorf("expected joined errors, got %v", joined)
+	}
+	if joined.Error() != "task 1: nope\ntask 2: task cancelled while await" {
+		t.Errorf("unexpected message: %q", joined.Error())
+	}
+
+	if quest.AwaitAllErr[int](t1) != nil {
+		t.Error("expected no error")
+	}
+}
+
+func TestAwait10(t *testing.T) {
+	ints := make([]quest.Task[int], 9)
+	for i := range ints {
+		ints[i] = quest.NewTask[int]()
+		ints[i].Resolve(i)
+	}
+	s := quest.NewTask[string]()
+	s.Cancel()
+
+	a, b, c, d, e, f, g, h, i, j := quest.Await10[int, int, int, int, int, int, int, int, int, string](
+		ints[0], ints[1], ints[2], ints[3], ints[4], ints[5], ints[6], ints[7], ints[8], s,
+	)
+	if *a != 0 || *b != 1 || *c != 2 || *d != 3 || *e != 4 ||
+		*f != 5 || *g != 6 || *h != 7 || *i != 8 {
+		t.Error("wrong values")
+	}
+	if j != nil {
+		t.Error("cancelled task should be nil")
+	}
+}
+
+func TestAwait2E(t *testing.T) {
+	err := errors.New("nope")
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[string]()
+	t1.Resolve(1)
+	t2.Fail(err)
+
+	a, b, joined := quest.Await2E[int, string](t1, t2)
+	if a != 1 || b != "" {
+		t.Errorf("unexpected values: %v, %q", a, b)
+	}
+	if !errors.Is(joined, err) || joined.Error() != "task 1: nope" {
+		t.Errorf("unexpected error: %v", joined)
+	}
+
+	t2.Reset()
+	t2.Resolve("one")
+	if _, _, joined := quest.Await2E[int, string](t1, t2); joined != nil {
+		t.Errorf("expected no error, got %v", joined)
+	}
+}
+
+func TestAwaitAllSliceErr(t *testing.T) {
+	err := errors.New("nope")
+	tasks := []quest.Task[int]{quest.NewTask[int](), quest.NewTask[int]()}
+	tasks[0].Resolve(1)
+	tasks[1].Fail(err)
+
+	results, joined := quest.AwaitAllSliceErr(tasks)
+	if !results[0].OK || results[0].Value != 1 || results[1].OK {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if !errors.Is(joined, err) || joined.Error() != "task 1: nope" {
+		t.Errorf("unexpected error: %v", joined)
+	}
+}
+
+func TestAwait10E(t *testing.T) {
+	tasks := make([]quest.Task[int], 10)
+	for i := range tasks {
+		tasks[i] = quest.NewTask[int]()
+		tasks[i].Resolve(i)
+	}
+	tasks[9].Reset()
+	tasks[9].Cancel()
+
+	_, b, _, _, _, _, _, _, i, j, err := quest.Await10E[int, int, int, int, int, int, int, int, int, int](
+		tasks[0], tasks[1], tasks[2], tasks[3], tasks[4],
+		tasks[5], tasks[6], tasks[7], tasks[8], tasks[9],
+	)
+	if b != 1 || i != 8 || j != 0 {
+		t.Errorf("unexpected values: %v, %v, %v", b, i, j)
+	}
+	if !errors.Is(err, quest.ErrCancelled) || err.Error() != "task 9: "+quest.ErrCancelled.Error() {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMeta(t *testing.T) {
+	type traceKey struct{}
+	task := quest.NewTask[int]()
+	if task.Meta(traceKey{}) != nil {
+		t.Error("expected no metadata")
+	}
+
+	task.SetMeta(traceKey{}, "abc")
+	task.Resolve(1)
+	task.Reset()
+	if task.Meta(traceKey{}) != "abc" {
+		t.Errorf("metadata should be kept across reset, got %v", task.Meta(traceKey{}))
+	}
+}
+
+func TestSetCancelError(t *testing.T) {
+	errClosed := errors.New("screen closed")
+	task := quest.NewTask[int]()
+	task.SetCancelError(errClosed)
+	task.Cancel()
+
+	if task.Error() != errClosed {
+		t.Errorf("expected the cancel error, got %v", task.Error())
+	}
+	if err := quest.AwaitAllErr[int](task); !errors.Is(err, errClosed) {
+		t.Errorf("expected the cancel error, got %v", err)
+	}
+
+	task.Reset()
+	if task.Error() != nil {
+		t.Error("pending task should have no error")
+	}
+
+	task.SetPanic(true)
+	go task.Cancel()
+	defer func() {
+		if r := recover(); r != errClosed {
+			t.Errorf("expected a panic with the cancel error, got %v", r)
+		}
+	}()
+	task.Await()
+}
+
+func TestStateChanges(t *testing.T) {
+	task := quest.NewTask[int]()
+	changes := task.StateChanges()
+
+	task.Resolve(1)
+	task.Reset()
+	task.Fail(errors.New("nope"))
+	task.Reset()
+	task.Cancel()
+
+	expected := []quest.Status{
+		quest.Resolved, quest.Pending, quest.Failed, quest.Pending, quest.Cancelled,
+	}
+	for i, status := range expected {
+		if got, _ := changes.Next().Await(); got != status {
+			t.Errorf("change %d: expected %v, got %v", i, status, got)
+		}
+	}
+
+	changes.Close()
+	task.Reset()
+	if _, ok := changes.Next().Await(); ok {
+		t.Error("closed stream should not receive changes")
+	}
+}
+
+func TestStateChangesDoNotBlock(t *testing.T) {
+	task := quest.NewTask[int]()
+	changes := task.StateChanges()
+
+	// the receiver's callbacks run while the task is settled
+	var seen atomic.Value
+	changes.Next().OnResolve(func() { seen.Store(task.Status()) })
+	task.Resolve(1)
+	if seen.Load() != quest.Resolved {
+		t.Errorf("expected the receiver to see the task resolved, got %v", seen.Load())
+	}
+
+	for i := 0; i < 100; i++ {
+		task.Reset()
+		task.Resolve(i)
+	}
+	buffered := 0
+	for changes.Next().IsDone() {
+		buffered++
+	}
+	if buffered > 16 {
+		t.Errorf("changes nobody reads should be dropped, got %v buffered", buffered)
+	}
+}
+
+func TestNewTasks(t *testing.T) {
+	tasks := quest.NewTasks[int](3)
+	for i, task := range tasks {
+		if i == 1 {
+			go task.Cancel()
+			continue
+		}
+		go task.Resolve(i * 10)
+	}
+
+	results := quest.AwaitAllSlice(tasks)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %v", len(results))
+	}
+	if results[0] != (quest.Result[int]{0, true}) ||
+		results[1].OK ||
+		results[2] != (quest.Result[int]{20, true}) {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestResetAfterAwaiters(t *testing.T) {
+	task := quest.NewTask[int]()
+	var seen atomic.Int32
+	for i := 0; i < 10; i++ {
+		go func() {
+			if n, _ := task.Await(); n == 1 {
+				seen.Add(1)
+			}
+		}()
+	}
+	for task.WaiterCount() < 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	task.Resolve(1)
+	if !task.ResetAfterAwaiters() {
+		t.Error("expected the task to be reset")
+	}
+	task.Resolve(2)
+	// The awaiters have the result, but may not have counted it yet.
+	deadline := time.Now().Add(time.Second)
+	for seen.Load() < 10 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if seen.Load() != 10 {
+		t.Errorf("all awaiters should see the first result, got %v", seen.Load())
+	}
+}
+
+func TestWaiterFairness(t *testing.T) {
+	task := quest.NewMultiTask[int]()
+	const waiters = 50
+	const rounds = 100
+
+	var received, finished atomic.Int32
+	for i := 0; i < waiters; i++ {
+		go func() {
+			for round := 1; round <= rounds; round++ {
+				if n, _ := task.Await(); n != round {
+					t.Errorf("waiter got %v on round %v", n, round)
+					return
+				}
+				received.Add(1)
+			}
+			finished.Add(1)
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for n := 1; n <= rounds; n++ {
+		// Every waiter must be blocked again before the next event.
+		for int(received.Load()) < waiters*(n-1) || task.WaiterCount() < waiters {
+			if time.Now().After(deadline) {
+				t.Fatalf("waiters starved on round %v", n)
+			}
+			time.Sleep(50 * time.Microsecond)
+		}
+		task.Resolve(n)
+	}
+
+	for finished.Load() < waiters && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if finished.Load() != waiters {
+		t.Errorf("%v waiters starved", waiters-finished.Load())
+	}
+}
+
+func TestWaiterReleaseOrder(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	task := quest.NewTask[int]()
+	const waiters = 20
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			task.Await()
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i)
+		for task.WaiterCount() < i+1 {
+			runtime.Gosched()
+		}
+	}
+
+	task.Resolve(1)
+	wg.Wait()
+
+	for i, n := range order {
+		if n != i {
+			t.Fatalf("waiters released out of order: %v", order)
+		}
+	}
+}
+
+func TestStartN(t *testing.T) {
+	tasks := quest.StartN(5, func(i int) int {
+		randomSleep()
+		return i * i
+	})
+	for i, r := range quest.AwaitAllSlice(tasks) {
+		if !r.OK || r.Value != i*i {
+			t.Errorf("replica %v: unexpected result %v", i, r)
+		}
+	}
+}
+
+func TestTiming(t *testing.T) {
+	task := quest.NewTask[int](quest.WithTiming())
+	time.Sleep(5 * time.Millisecond)
+	task.Resolve(1)
+
+	times := task.Timestamps()
+	if times.Created.IsZero() || times.Resolved.IsZero() || !times.Cancelled.IsZero() {
+		t.Errorf("unexpected timestamps: %+v", times)
+	}
+	if d := task.Duration(); d < 5*time.Millisecond || d != times.Resolved.Sub(times.Created) {
+		t.Errorf("unexpected duration: %v", d)
+	}
+
+	task.Reset()
+	if !task.Timestamps().Resolved.IsZero() || task.Timestamps().Reset.IsZero() {
+		t.Errorf("reset should restart the timing: %+v", task.Timestamps())
+	}
+	task.Cancel()
+	if task.Timestamps().Cancelled.IsZero() {
+		t.Error("expected the cancel time")
+	}
+
+	if quest.NewTask[int]().Duration() != 0 {
+		t.Error("timing should be opt-in")
+	}
+}
+
+func TestAllocTaskIn(t *testing.T) {
+	pool := mud.NewPool()
+	quest.PreAllocTasksIn[int](pool, 1)
+
+	task := quest.AllocTaskIn[int](pool)
+	task.Resolve(1)
+	quest.FreeTaskIn(pool, task)
+
+	reused := quest.AllocTaskIn[int](pool)
+	if reused != task {
+		t.Error("expected the freed task to be reused")
+	}
+	if reused.IsDone() {
+		t.Error("reused task should be reset")
+	}
+}
+
+func TestPooledTaskReuse(t *testing.T) {
+	pool := mud.NewPool()
+	first := quest.NewTask[int](quest.WithName("owner-a"), quest.WithPool(pool), quest.WithAutoReset())
+	id := first.ID()
+	first.SetMeta("key", "secret")
+	stale := 0
+	first.OnResolve(func() { stale++ })
+	quest.FreeTaskIn(pool, first)
+
+	second := quest.NewTask[int](quest.WithName("owner-b"), quest.WithPool(pool))
+	if second != first {
+		t.Fatal("expected the freed task to be reused")
+	}
+	calls := 0
+	second.OnResolve(func() { calls++ })
+	second.Resolve(1)
+
+	if stale != 0 || calls != 1 {
+		t.Errorf("only the callback of the new owner should run, got %v and %v", stale, calls)
+	}
+	if second.Name() != "owner-b" || second.Meta("key") != nil || second.ID() == id {
+		t.Errorf("reused task kept the state of its previous owner: %v, %v, %v", second.Name(), second.Meta("key"), second.ID())
+	}
+	if _, ok := second.Await(); !ok || !second.IsDone() {
+		t.Error("reused task should not auto reset")
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	pool := mud.NewPool()
+	ints := []quest.Task[int]{quest.AllocTaskIn[int](pool), quest.AllocTaskIn[int](pool)}
+	quest.AllocTaskIn[string](pool)
+	quest.NewTask[string](quest.WithPool(pool))
+	quest.FreeTaskIn(pool, ints[0])
+
+	stats := quest.PoolStatsIn(pool)
+	expected := []quest.PoolTypeStats{
+		{Type: "int", Allocs: 2, Frees: 1, Live: 1},
+		{Type: "string", Allocs: 2, Frees: 0, Live: 2},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	for i := range expected {
+		if stats[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], stats[i])
+		}
+	}
+}
+
+func TestTaskOptions(t *testing.T) {
+	errClosed := errors.New("closed")
+	task := quest.NewTask[int](
+		quest.WithName("options"),
+		quest.WithDefaultValue(-1),
+		quest.WithCancelError(errClosed),
+		quest.WithTaskDeadline(time.Now().Add(5*time.Millisecond)),
+	)
+	if n, ok := task.Await(); ok || n != -1 {
+		t.Errorf("expected the default value after the deadline, got %v", n)
+	}
+	if task.Error() != quest.ErrTimeout {
+		t.Errorf("expected a timeout, got %v", task.Error())
+	}
+	task.Reset()
+	task.Cancel()
+	if task.Error() != errClosed {
+		t.Errorf("expected the cancel error, got %v", task.Error())
+	}
+
+	events := quest.NewTask[int](quest.WithAutoReset())
+	events.Resolve(1)
+	if !events.TryResolve(2) {
+		t.Error("auto-reset task should accept every Resolve()")
+	}
+
+	pool := mud.NewPool()
+	pooled := quest.NewTask[int](quest.WithPool(pool))
+	quest.FreeTaskIn(pool, pooled)
+	if quest.NewTask[int](quest.WithPool(pool)) != pooled {
+		t.Error("expected the task to come from the pool")
+	}
+
+	converted := quest.NewTask[int64](quest.WithDefaultValue(5))
+	converted.Cancel()
+	if v, _ := converted.Await(); v != 5 {
+		t.Errorf("expected the default value to be converted, got %v", v)
+	}
+
+	for _, opt := range []quest.TaskOption{
+		quest.WithDefaultValue(1),
+		quest.WithDefaultValue(1.5),
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("default value of the wrong type should panic")
+				}
+			}()
+			quest.NewTask[string](opt)
+		}()
+	}
+	func() {
+		defer func() {
+			if r, _ := recover().(string); !strings.HasPrefix(r, "quest: WithDefaultValue") {
+				t.Errorf("expected the option error, got %v", r)
+			}
+		}()
+		quest.NewTask[complex128](quest.WithDefaultValue(5))
+	}()
+	defer func() {
+		if recover() == nil {
+			t.Error("default value that doesn't fit should panic")
+		}
+	}()
+	quest.NewTask[uint8](quest.WithDefaultValue(300))
+}
+
+func TestTaskError(t *testing.T) {
+	err := errors.New("nope")
+	leaf := quest.NewTask[int](quest.WithName("leaf"))
+	other := quest.NewTask[int]()
+	leaf.Fail(err)
+	other.Resolve(1)
+
+	joined := quest.Join2[int, int](other, leaf)
+	joined.Await()
+	outer := quest.AwaitAllErr[quest.Pair[int, int]](joined)
+
+	var taskErr *quest.TaskError
+	if !errors.As(outer, &taskErr) || taskErr.ID != joined.ID() {
+		t.Fatalf("expected the error of the joined task, got %v", outer)
+	}
+	found, ok := quest.LeafTaskError(outer)
+	if !ok || found.ID != leaf.ID() || found.Name != "leaf" || found.Err != err {
+		t.Errorf("expected the leaf task, got %+v", found)
+	}
+	if outer.Error() != "task 0: task 1: nope" {
+		t.Errorf("unexpected message: %v", outer)
+	}
+}
+
+func TestStartAfter(t *testing.T) {
+	start := time.Now()
+	task := quest.StartAfter(5*time.Millisecond, func() int { return 1 })
+	if n, _ := task.Await(); n != 1 || time.Since(start) < 5*time.Millisecond {
+		t.Errorf("expected 1 after the delay, got %v", n)
+	}
+
+	var ran atomic.Bool
+	skipped := quest.StartAfter(5*time.Millisecond, func() int {
+		ran.Store(true)
+		return 2
+	})
+	skipped.Cancel()
+	time.Sleep(10 * time.Millisecond)
+	if ran.Load() {
+		t.Error("cancelled task should not run fn")
+	}
+}
@@ -1,35 +1,175 @@
 package quest
 
 import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
 	"github.com/nvlled/mud"
 )
 
 var taskPool = mud.NewPool()
 
+// Allocation counters of the tasks of one type in a pool,
+// returned by PoolStats().
+type PoolTypeStats struct {
+	// The type of the values of the tasks, e.g. "int"
+	// for Task[int].
+	Type   string
+	Allocs int64
+	Frees  int64
+	// The number of tasks allocated but not yet freed.
+	// A count that keeps growing points to a leak.
+	Live int64
+}
+
+type poolKey struct {
+	pool *mud.Pool
+	typ  reflect.Type
+}
+
+type poolCounters struct {
+	allocs atomic.Int64
+	frees  atomic.Int64
+}
+
+// The counters of each pool and task type.
+var poolStats sync.Map
+
+func countersOf[T any](pool *mud.Pool) *poolCounters {
+	key := poolKey{pool, reflect.TypeOf((*T)(nil)).Elem()}
+	if counters, ok := poolStats.Load(key); ok {
+		return counters.(*poolCounters)
+	}
+	counters, _ := poolStats.LoadOrStore(key, &poolCounters{})
+	return counters.(*poolCounters)
+}
+
+// Returns the allocation counters of the default pool,
+// for each type of task allocated so far, sorted by type.
+// Example:
+//
+//	for _, s := range PoolStats() {
+//	  fmt.Printf("Task[%s]: %d live\n", s.Type, s.Live)
+//	}
+func PoolStats() []PoolTypeStats {
+	return PoolStatsIn(taskPool)
+}
+
+// Same as PoolStats(), but for the given pool.
+// This includes the tasks created with WithPool().
+func PoolStatsIn(pool *mud.Pool) []PoolTypeStats {
+	var stats []PoolTypeStats
+	poolStats.Range(func(key, value any) bool {
+		if key.(poolKey).pool != pool {
+			return true
+		}
+		counters := value.(*poolCounters)
+		// Loaded first, so that Live can't be negative.
+		frees := counters.frees.Load()
+		allocs := counters.allocs.Load()
+		stats = append(stats, PoolTypeStats{
+			Type:   key.(poolKey).typ.String(),
+			Allocs: allocs,
+			Frees:  frees,
+			Live:   allocs - frees,
+		})
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats
+}
+
 func init() {
 	PreAllocTasks[Void](250)
 }
 
 // Pre-allocate a number of tasks of the given type.
 func PreAllocTasks[T any](numTasks int) {
-	mud.PreAlloc(taskPool, newTask[T], numTasks)
+	PreAllocTasksIn[T](taskPool, numTasks)
+}
+
+// Same as PreAllocTasks(), but in the given pool
+// instead of the default one.
+func PreAllocTasksIn[T any](pool *mud.Pool, numTasks int) {
+	mud.PreAlloc(pool, newTask[T], numTasks)
 }
 
 // Allocate a task using an object pool.
 // Free the task afterwards with Free().
 // Use only when gc is a concern.
 func AllocTask[T any]() Task[T] {
-	task := mud.Alloc(taskPool, newTask[T])
+	return AllocTaskIn[T](taskPool)
+}
+
+// Same as AllocTask(), but uses the given pool instead
+// of the default one, so that independent subsystems
+// can size their pools separately.
+// Free the task afterwards with FreeTaskIn(), with the same pool.
+// Example:
+//
+//	var renderPool = mud.NewPool()
+//	task := AllocTaskIn[Frame](renderPool)
+//	defer FreeTaskIn(renderPool, task)
+func AllocTaskIn[T any](pool *mud.Pool) Task[T] {
+	task := mud.Alloc(pool, newTask[T])
 	task.Reset()
+	countersOf[T](pool).allocs.Add(1)
 	return task
 }
 
 // Free a task that was previously Alloc()'d.
 func FreeTask[T any](task Task[T]) {
+	FreeTaskIn(taskPool, task)
+}
+
+// Free a task that was previously AllocTaskIn()'d
+// from the same pool.
+func FreeTaskIn[T any](pool *mud.Pool, task Task[T]) {
 	object, ok := task.(*taskImpl[T])
 	if !ok {
 		return
 	}
+	object.reportIfIdle()
 	object.Cancel()
-	mud.Free(taskPool, object)
+	object.recycle()
+	mud.Free(pool, object)
+	countersOf[T](pool).frees.Add(1)
+}
+
+// Clears everything the previous owner left on the task,
+// so that the next owner gets it as if it was new, with
+// a new ID. The lock and the waiter count are kept, and
+// cycle is incremented to drop the pending idle check.
+func (task *taskImpl[T]) recycle() {
+	task.resolveMu.Lock()
+	defer task.resolveMu.Unlock()
+
+	var empty T
+	task.id = idGen.Add(1)
+	task.value = empty
+	task.defaultValue = empty
+	task.status = taskPending
+	task.autoReset = false
+	task.overwrite = false
+	task.cancelResolved = false
+	task.panics = false
+	task.failed = false
+	task.opts = taskOptions{}
+	task.onCancel = nil
+	task.deferred = nil
+	task.watchers = nil
+	task.meta = nil
+	task.changes = nil
+	task.history = nil
+	task.pendingChanges = nil
+	task.done = nil
+	task.nextDone = nil
+	task.settled = nil
+	task.err = nil
+	task.cancelErr = nil
+	task.awaited = false
+	task.cycle++
+	task.times = Timestamps{}
 }
@@ -0,0 +1,35 @@
+package quest
+
+// Returns n tasks that are all settled with the result
+// of src. Each task can be cancelled or reset on its own,
+// without affecting src or the other tasks, so one result
+// can drive several independent chains.
+// If src fails, the tasks fail with its error,
+// wrapped in a TaskError.
+// Example:
+//
+//	copies := Tee(fetchConfig(), 2)
+//	go applyConfig(copies[0])
+//	go cacheConfig(copies[1])
+func Tee[T any](src Awaitable[T], n int) []Task[T] {
+	tasks := make([]Task[T], n)
+	for i := range tasks {
+		tasks[i] = newTask[T]()
+	}
+
+	go func() {
+		value, ok := src.Await()
+		if ok {
+			for _, task := range tasks {
+				task.Resolve(value)
+			}
+			return
+		}
+		err := withIdentity(src, errorOf(src))
+		for _, task := range tasks {
+			task.Fail(err)
+		}
+	}()
+
+	return tasks
+}
@@ -0,0 +1,28 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestTee(t *testing.T) {
+	src := quest.NewTask[int]()
+	copies := quest.Tee[int](src, 3)
+
+	copies[1].Cancel()
+	src.Resolve(5)
+
+	if n, ok := copies[0].Await(); n != 5 || !ok {
+		t.Errorf("expected 5, got %v", n)
+	}
+	if _, ok := copies[1].Await(); ok {
+		t.Error("cancelled copy should stay cancelled")
+	}
+	if n, ok := copies[2].Await(); n != 5 || !ok {
+		t.Errorf("expected 5, got %v", n)
+	}
+	if src.IsCancelled() {
+		t.Error("cancelling a copy should not affect the source")
+	}
+}
@@ -0,0 +1,114 @@
+package quest
+
+// Two values of possibly different types,
+// e.g. the combined result of Join2().
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Three values of possibly different types,
+// e.g. the combined result of Join3().
+type Triple[A, B, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Returns a task that is resolved with the results of
+// both tasks once they are resolved. If any of them is
+// cancelled or failed, the task fails with its error,
+// prefixed with the task's index.
+// Example:
+//
+//	profile := Join2(fetchUser(id), fetchAvatar(id))
+//	p, ok := profile.Await()
+//	// p.First is the user, p.Second the avatar
+func Join2[A, B any](t1 Awaitable[A], t2 Awaitable[B]) Task[Pair[A, B]] {
+	task := newTask[Pair[A, B]]()
+	go func() {
+		a, ok := t1.Await()
+		if !ok {
+			task.Fail(indexedError(0, t1))
+			return
+		}
+		b, ok := t2.Await()
+		if !ok {
+			task.Fail(indexedError(1, t2))
+			return
+		}
+		task.Resolve(Pair[A, B]{a, b})
+	}()
+	return task
+}
+
+// Same as Join2(), with three tasks.
+func Join3[A, B, C any](t1 Awaitable[A], t2 Awaitable[B], t3 Awaitable[C]) Task[Triple[A, B, C]] {
+	task := newTask[Triple[A, B, C]]()
+	go func() {
+		a, ok := t1.Await()
+		if !ok {
+			task.Fail(indexedError(0, t1))
+			return
+		}
+		b, ok := t2.Await()
+		if !ok {
+			task.Fail(indexedError(1, t2))
+			return
+		}
+		c, ok := t3.Await()
+		if !ok {
+			task.Fail(indexedError(2, t3))
+			return
+		}
+		task.Resolve(Triple[A, B, C]{a, b, c})
+	}()
+	return task
+}
+
+// Same as Await2E(), but returns the values as a Pair,
+// so that they can be passed on as one value.
+// Example:
+//
+//	profile, err := AwaitPair(fetchUser(id), fetchAvatar(id))
+func AwaitPair[A, B any](t1 Awaitable[A], t2 Awaitable[B]) (Pair[A, B], error) {
+	a, b, err := Await2E(t1, t2)
+	return Pair[A, B]{a, b}, err
+}
+
+// Same as Await3E(), but returns the values as a Triple.
+func AwaitTriple[A, B, C any](t1 Awaitable[A], t2 Awaitable[B], t3 Awaitable[C]) (Triple[A, B, C], error) {
+	a, b, c, err := Await3E(t1, t2, t3)
+	return Triple[A, B, C]{a, b, c}, err
+}
+
+// Returns a stream of pairs made of the values of s1 and
+// s2, taken one from each, in order. The stream is closed
+// once either stream is closed and drained, with its error
+// if it has one. Closing the returned stream stops the pairing.
+// Example:
+//
+//	entries := Zip2(names, scores)
+//	entry, ok := entries.Next().Await()
+//	// entry.First is a name, entry.Second its score
+func Zip2[A, B any](s1 *Stream[A], s2 *Stream[B]) *Stream[Pair[A, B]] {
+	out := NewStream[Pair[A, B]](0)
+	go func() {
+		for {
+			a, ok := s1.Next().Await()
+			if !ok {
+				out.CloseWithError(s1.Error())
+				return
+			}
+			b, ok := s2.Next().Await()
+			if !ok {
+				out.CloseWithError(s2.Error())
+				return
+			}
+			if _, ok := out.Send(Pair[A, B]{a, b}).Await(); !ok {
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,58 @@
+package quest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestJoin(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[string]()
+	t1.Resolve(1)
+	t2.Resolve("one")
+
+	pair, ok := quest.Join2[int, string](t1, t2).Await()
+	if !ok || pair.First != 1 || pair.Second != "one" {
+		t.Errorf("unexpected pair: %v", pair)
+	}
+
+	err := errors.New("nope")
+	t3 := quest.NewTask[bool]()
+	t3.Fail(err)
+	joined := quest.Join3[int, string, bool](t1, t2, t3)
+	if _, ok := joined.Await(); ok || !errors.Is(joined.Error(), err) {
+		t.Errorf("expected the error of the third task, got %v", joined.Error())
+	}
+}
+
+func TestAwaitPair(t *testing.T) {
+	t1 := quest.NewTask[int]()
+	t2 := quest.NewTask[string]()
+	t1.Resolve(1)
+	t2.Resolve("one")
+
+	pair, err := quest.AwaitPair[int, string](t1, t2)
+	if err != nil || pair.First != 1 || pair.Second != "one" {
+		t.Errorf("unexpected pair: %v, %v", pair, err)
+	}
+
+	t3 := quest.NewTask[bool]()
+	t3.Cancel()
+	if _, err := quest.AwaitTriple[int, string, bool](t1, t2, t3); !errors.Is(err, quest.ErrCancelled) {
+		t.Errorf("expected the third task to be cancelled, got %v", err)
+	}
+}
+
+func TestZip2(t *testing.T) {
+	zipped := quest.Zip2(quest.StreamOf(1, 2, 3), quest.StreamOf("one", "two"))
+	for _, expected := range []quest.Pair[int, string]{{1, "one"}, {2, "two"}} {
+		if pair, _ := zipped.Next().Await(); pair != expected {
+			t.Errorf("expected %v, got %v", expected, pair)
+		}
+	}
+	if _, ok := zipped.Next().Await(); ok {
+		t.Error("zipped stream should be closed with the shortest stream")
+	}
+}
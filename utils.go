@@ -1,8 +1,67 @@
 package quest
 
+import "fmt"
+
 func asPointer[T any](value T, ok bool) *T {
 	if !ok {
 		return nil
 	}
 	return &value
 }
+
+// Awaits in the background, and delivers the result on the channel.
+// Tasks are awaited with AwaitAbortable(), so that abort()
+// doesn't leave a goroutine behind. For other awaitables,
+// abort() only stops the delivery.
+func awaitChan[T any](a Awaitable[T]) (<-chan Result[T], func()) {
+	if t, ok := a.(Task[T]); ok {
+		return t.AwaitAbortable()
+	}
+	result := make(chan Result[T], 1)
+	go func() {
+		value, ok := a.Await()
+		result <- Result[T]{value, ok}
+		close(result)
+	}()
+	return result, func() {}
+}
+
+// Returns the error of the awaitable if it has one.
+func errorOf(a any) error {
+	if t, ok := a.(interface{ Error() error }); ok {
+		return t.Error()
+	}
+	return nil
+}
+
+// Same as errorOf(), but returns ErrCancelled
+// instead of nil.
+func cancelErrorOf(a any) error {
+	if err := errorOf(a); err != nil {
+		return err
+	}
+	return ErrCancelled
+}
+
+// Returns the cancel error of the awaitable, wrapped
+// in a TaskError, and prefixed with its index.
+func indexedError(index int, a any) error {
+	return fmt.Errorf("task %d: %w", index, withIdentity(a, cancelErrorOf(a)))
+}
+
+// Wraps err in a TaskError if the awaitable is a task.
+// Returns err as is if it's nil, or already a TaskError
+// of the task, e.g. set by Fail() WithStackTrace().
+func withIdentity(a any, err error) error {
+	t, ok := a.(interface {
+		ID() int64
+		Name() string
+	})
+	if !ok || err == nil {
+		return err
+	}
+	if taskErr, ok := err.(*TaskError); ok && taskErr.ID == t.ID() {
+		return err
+	}
+	return &TaskError{ID: t.ID(), Name: t.Name(), Err: err}
+}
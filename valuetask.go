@@ -0,0 +1,50 @@
+package quest
+
+// An Awaitable that is either a value known in advance,
+// or another awaitable. It is a plain value: returning
+// an already known result doesn't allocate a task, which
+// matters on hot paths where most results are synchronous.
+// The zero ValueTask is resolved with the zero value.
+// Example:
+//
+//	func (c *Cache) Get(key string) ValueTask[Item] {
+//	  if item, ok := c.items[key]; ok {
+//	    return ValueTaskOf(item)
+//	  }
+//	  return ValueTaskFrom[Item](c.load(key))
+//	}
+type ValueTask[T any] struct {
+	value T
+	task  Awaitable[T]
+}
+
+// Returns a ValueTask resolved with the value.
+func ValueTaskOf[T any](value T) ValueTask[T] {
+	return ValueTask[T]{value: value}
+}
+
+// Returns a ValueTask that awaits the awaitable.
+func ValueTaskFrom[T any](a Awaitable[T]) ValueTask[T] {
+	return ValueTask[T]{task: a}
+}
+
+// Returns the value right away, or awaits the awaitable.
+func (v ValueTask[T]) Await() (T, bool) {
+	if v.task != nil {
+		return v.task.Await()
+	}
+	return v.value, true
+}
+
+// Returns true if the result is known without waiting.
+func (v ValueTask[T]) IsSync() bool {
+	return v.task == nil
+}
+
+// Returns the error of the awaitable, if any.
+func (v ValueTask[T]) Error() error {
+	if v.task != nil {
+		return errorOf(v.task)
+	}
+	return nil
+}
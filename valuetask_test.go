@@ -0,0 +1,27 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestValueTask(t *testing.T) {
+	ready := quest.ValueTaskOf(1)
+	if n, ok := ready.Await(); !ok || n != 1 || !ready.IsSync() {
+		t.Errorf("expected a synchronous 1, got %v", n)
+	}
+
+	task := quest.NewTask[int]()
+	pending := quest.ValueTaskFrom[int](task)
+	go task.Resolve(2)
+	if n, ok := pending.Await(); !ok || n != 2 || pending.IsSync() {
+		t.Errorf("expected 2 from the task, got %v", n)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		quest.ValueTaskOf(3).Await()
+	}); allocs != 0 {
+		t.Errorf("expected no allocation, got %v", allocs)
+	}
+}
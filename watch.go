@@ -0,0 +1,47 @@
+package quest
+
+import "sync"
+
+// Calls fn with the value of every Resolve() of the task
+// from now on, including the ones after a Reset(), until
+// unwatch() is called. Useful for tasks that are reset in
+// a loop, or created with NewMultiTask() or NewLatestTask(),
+// to be used as event sources.
+// fn is called in the goroutine that resolves the task.
+// Example:
+//
+//	unwatch := Watch(clicked, func(pos Point) {
+//	  fmt.Println("clicked at", pos)
+//	})
+//	defer unwatch()
+func Watch[T any](task Task[T], fn func(T)) (unwatch func()) {
+	impl, ok := task.(interface{ watch(func(T)) func() })
+	if !ok {
+		return watchLoop(task, fn)
+	}
+
+	return impl.watch(fn)
+}
+
+// Watches other Task implementations with AwaitNext().
+// Resolutions that happen while fn is running are missed.
+func watchLoop[T any](task Task[T], fn func(T)) func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			value, ok := task.AwaitNext()
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if ok {
+				fn(value)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
@@ -0,0 +1,28 @@
+package quest_test
+
+import (
+	"testing"
+
+	"github.com/nvlled/quest"
+)
+
+func TestWatch(t *testing.T) {
+	task := quest.NewTask[int]()
+	values := []int{}
+	unwatch := quest.Watch(task, func(n int) {
+		values = append(values, n)
+	})
+
+	task.Resolve(1)
+	task.Reset()
+	task.Cancel()
+	task.Reset()
+	task.Resolve(2)
+	unwatch()
+	task.Reset()
+	task.Resolve(3)
+
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("expected [1 2], got %v", values)
+	}
+}
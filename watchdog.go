@@ -0,0 +1,64 @@
+package quest
+
+import (
+	"sync"
+	"time"
+)
+
+// A Watchdog supervises a long-running worker.
+// The worker must call Kick() at least once every interval,
+// otherwise the watchdog's task fails with ErrTimeout.
+// Example:
+//
+//	wd := NewWatchdog(5 * time.Second)
+//	go func() {
+//	  for job := range jobs {
+//	    process(job)
+//	    wd.Kick()
+//	  }
+//	  wd.Stop()
+//	}()
+//	if _, ok := wd.Task().Await(); !ok {
+//	  log.Println("worker hung:", wd.Task().Error())
+//	}
+type Watchdog struct {
+	mu       sync.Mutex
+	interval time.Duration
+	timer    *time.Timer
+	task     *taskImpl[Void]
+}
+
+// Creates a watchdog, and starts the first interval.
+func NewWatchdog(interval time.Duration) *Watchdog {
+	wd := &Watchdog{
+		interval: interval,
+		task:     newTask[Void](),
+	}
+	wd.timer = time.AfterFunc(interval, func() {
+		wd.task.Fail(ErrTimeout)
+	})
+	wd.task.Defer(func() { wd.timer.Stop() })
+	return wd
+}
+
+// Signals that the worker is alive, and restarts the interval.
+// No effect if the watchdog is already done.
+func (wd *Watchdog) Kick() {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if wd.task.IsDone() {
+		return
+	}
+	wd.timer.Reset(wd.interval)
+}
+
+// Stops the watchdog, resolving its task.
+func (wd *Watchdog) Stop() {
+	wd.task.Resolve(None)
+}
+
+// Returns the task that is resolved by Stop(),
+// or fails with ErrTimeout when an interval is missed.
+func (wd *Watchdog) Task() VoidTask {
+	return wd.task
+}
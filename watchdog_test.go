@@ -0,0 +1,32 @@
+package quest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nvlled/quest"
+)
+
+func TestWatchdog(t *testing.T) {
+	wd := quest.NewWatchdog(20 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		time.Sleep(5 * time.Millisecond)
+		wd.Kick()
+	}
+	if wd.Task().IsDone() {
+		t.Error("watchdog should not fail while kicked")
+	}
+
+	if _, ok := wd.Task().Await(); ok {
+		t.Error("watchdog should fail when not kicked")
+	}
+	if wd.Task().Error() != quest.ErrTimeout {
+		t.Errorf("expected ErrTimeout, got %v", wd.Task().Error())
+	}
+
+	wd = quest.NewWatchdog(time.Second)
+	wd.Stop()
+	if _, ok := wd.Task().Await(); !ok {
+		t.Error("stopped watchdog should be resolved")
+	}
+}